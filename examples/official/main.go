@@ -8,7 +8,7 @@ import (
 )
 
 func main() {
-	client := elasticutil.MustNewClient("")
+	client := elasticutil.MustNewClientWithOptions([]string{""})
 
 	response, err := client.Search(
 		context.Background(),
@@ -0,0 +1,198 @@
+package v7
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// BulkConfig hold all information to Bulk method.
+//
+// Index is used for every item that does not set its own index.
+type BulkConfig struct {
+	Index   string
+	Items   []BulkItem
+	Refresh Refresh
+}
+
+// BulkItem represents a single operation of the Bulk method.
+//
+// Document is the document for index and create actions, and the update body
+// (e.g. {"doc": {...}}) for update actions. It is ignored by delete actions.
+type BulkItem struct {
+	Action          BulkAction
+	Index           string
+	ID              string
+	Routing         string
+	Document        interface{}
+	IfSeqNo         *int
+	IfPrimaryTerm   *int
+	RetryOnConflict int
+}
+
+// Bulk sends all @config's Items in a single _bulk request. Failures of
+// single items do not fail the request and are reported in each
+// BulkResponseItem.
+func (c *esClient) Bulk(ctx context.Context, config BulkConfig) (BulkResponse, error) {
+	const op = errors.Op("v7.Client.Bulk")
+
+	enrichLogWithIndexes(ctx, getBulkIndexes(config))
+
+	response, err := c.doBulk(ctx, config)
+	if err != nil {
+		return BulkResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseBulkResponse(response)
+	if err != nil {
+		return BulkResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doBulk(ctx context.Context, config BulkConfig) (*esapi.Response, error) {
+	const op = errors.Op("doBulk")
+
+	body, err := getBulkBody(config)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.BulkRequest){
+		c.client.Bulk.WithContext(ctx),
+	}
+	if config.Index != "" {
+		options = append(options, c.client.Bulk.WithIndex(config.Index))
+	}
+	if config.Refresh != "" {
+		options = append(options, c.client.Bulk.WithRefresh(string(config.Refresh)))
+	}
+
+	response, err := c.client.Bulk(body, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+func getBulkBody(config BulkConfig) (io.Reader, error) {
+	const op = errors.Op("getBulkBody")
+
+	if len(config.Items) == 0 {
+		return nil, errors.E(op, requiredFieldError("Items"))
+	}
+
+	var b bytes.Buffer
+	for i, item := range config.Items {
+		err := writeBulkItem(&b, item, config.Index)
+		if err != nil {
+			return nil, errors.E(op, err, errors.KV("item", i))
+		}
+	}
+
+	return &b, nil
+}
+
+func writeBulkItem(b *bytes.Buffer, item BulkItem, defaultIndex string) error {
+	switch item.Action {
+	case BulkActionIndex, BulkActionCreate, BulkActionUpdate, BulkActionDelete:
+	default:
+		return errors.New("bulk action is not supported: " + string(item.Action))
+	}
+	if item.Index == "" && defaultIndex == "" {
+		return requiredFieldError("Index")
+	}
+	if item.ID == "" && item.Action != BulkActionIndex && item.Action != BulkActionCreate {
+		return requiredFieldError("ID")
+	}
+	if item.Document == nil && item.Action != BulkActionDelete {
+		return requiredFieldError("Document")
+	}
+
+	meta := make(map[string]interface{})
+	if item.Index != "" {
+		meta["_index"] = item.Index
+	}
+	if item.ID != "" {
+		meta["_id"] = item.ID
+	}
+	if item.Routing != "" {
+		meta["routing"] = item.Routing
+	}
+	if item.IfSeqNo != nil {
+		meta["if_seq_no"] = *item.IfSeqNo
+	}
+	if item.IfPrimaryTerm != nil {
+		meta["if_primary_term"] = *item.IfPrimaryTerm
+	}
+	if item.RetryOnConflict > 0 {
+		meta["retry_on_conflict"] = item.RetryOnConflict
+	}
+
+	header, err := json.Marshal(map[string]interface{}{string(item.Action): meta})
+	if err != nil {
+		return err
+	}
+	b.Write(header)
+	b.WriteByte('\n')
+
+	if item.Action == BulkActionDelete {
+		return nil
+	}
+
+	document, err := compactDocument(item.Document)
+	if err != nil {
+		return err
+	}
+	b.Write(document)
+	b.WriteByte('\n')
+
+	return nil
+}
+
+// compactDocument returns the JSON representation of @document in a single
+// line, as required by the NDJSON body of the _bulk request.
+func compactDocument(document interface{}) (json.RawMessage, error) {
+	body, err := encodeBody(document)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var compacted bytes.Buffer
+	err = json.Compact(&compacted, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return compacted.Bytes(), nil
+}
+
+func getBulkIndexes(config BulkConfig) []string {
+	indexes := make([]string, 0, len(config.Items)+1)
+	indexes = append(indexes, config.Index)
+	for _, item := range config.Items {
+		indexes = append(indexes, item.Index)
+	}
+	return uniqueIndexes(indexes)
+}
@@ -0,0 +1,410 @@
+package v7
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+
+	"github.com/arquivei/elasticutil/official/v7/internal/retrier"
+)
+
+const (
+	defaultBulkFlushBytes    = 5 * 1024 * 1024
+	defaultBulkFlushCount    = 1000
+	defaultBulkFlushInterval = 30 * time.Second
+	defaultBulkMaxRetries    = 3
+)
+
+// ErrBulkIndexerClosed is returned when an item is added to a BulkIndexer
+// that is already closed.
+var ErrBulkIndexerClosed = errors.New("bulk indexer is closed")
+
+// BulkIndexer sends items to elasticsearch in the background, grouping them
+// in _bulk requests.
+type BulkIndexer interface {
+	// Add enqueues the @item. It blocks while the queue is full, until
+	// there is room for the item or the @ctx is done.
+	Add(ctx context.Context, item BulkIndexerItem) error
+	// Close stops accepting new items and waits until all the enqueued
+	// items are flushed or the @ctx is done. When the @ctx is done first,
+	// the pending retries are abandoned and their items fail.
+	Close(ctx context.Context) error
+	// Stats returns the counters of the BulkIndexer.
+	Stats() BulkIndexerStats
+}
+
+// BulkIndexerConfig hold all information to NewBulkIndexer function.
+//
+// A worker flushes its items when it reaches FlushCount items, FlushBytes
+// bytes or when FlushInterval has elapsed since the last flush, whichever
+// comes first. Items rejected by elasticsearch with a status 429, and whole
+// _bulk requests that fail with an error accepted by IsRetryable, are
+// retried up to MaxRetries times, waiting RetryBackoff between attempts. A
+// zero MaxRetries uses the default and a negative one disables retries.
+type BulkIndexerConfig struct {
+	Index         string
+	NumWorkers    int
+	QueueSize     int
+	FlushBytes    int
+	FlushCount    int
+	FlushInterval time.Duration
+	MaxRetries    int
+	// RetryBackoff receives the retry attempt, starting at 1.
+	RetryBackoff func(attempt int) time.Duration
+	Refresh      Refresh
+	// OnError is called when a whole _bulk request fails.
+	OnError func(context.Context, error)
+}
+
+// BulkIndexerItem represents an item of the BulkIndexer with optional
+// callbacks that are called after it is flushed.
+type BulkIndexerItem struct {
+	BulkItem
+	OnSuccess func(context.Context, BulkItem, BulkResponseItem)
+	OnFailure func(context.Context, BulkItem, BulkResponseItem, error)
+}
+
+// BulkIndexerStats represents the counters of a BulkIndexer.
+type BulkIndexerStats struct {
+	NumAdded    uint64
+	NumFlushed  uint64
+	NumFailed   uint64
+	NumRetried  uint64
+	NumRequests uint64
+}
+
+type bulkIndexer struct {
+	client Client
+	config BulkIndexerConfig
+	queue  chan bulkIndexerEntry
+	wg     sync.WaitGroup
+
+	// ctx is used by the workers and canceled when Close gives up.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// done is closed by Close. The queue is only closed after the Adds in
+	// flight return, so they never send on a closed queue.
+	mu       sync.RWMutex
+	closed   bool
+	done     chan struct{}
+	inFlight sync.WaitGroup
+
+	numAdded    atomic.Uint64
+	numFlushed  atomic.Uint64
+	numFailed   atomic.Uint64
+	numRetried  atomic.Uint64
+	numRequests atomic.Uint64
+}
+
+type bulkIndexerEntry struct {
+	item BulkIndexerItem
+	size int
+}
+
+// NewBulkIndexer returns a BulkIndexer that uses the @client's Bulk method.
+// Workers are started right away and run until Close is called. Callbacks
+// are called from the workers with a context that is canceled when Close
+// gives up waiting for them.
+func NewBulkIndexer(client Client, config BulkIndexerConfig) (BulkIndexer, error) {
+	const op = errors.Op("v7.NewBulkIndexer")
+
+	if client == nil {
+		return nil, errors.E(op, requiredFieldError("client"))
+	}
+
+	config = withBulkIndexerDefaults(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bi := &bulkIndexer{
+		client: client,
+		config: config,
+		queue:  make(chan bulkIndexerEntry, config.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	for i := 0; i < config.NumWorkers; i++ {
+		bi.wg.Add(1)
+		go bi.work()
+	}
+
+	return bi, nil
+}
+
+func withBulkIndexerDefaults(config BulkIndexerConfig) BulkIndexerConfig {
+	if config.NumWorkers <= 0 {
+		config.NumWorkers = runtime.NumCPU()
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = config.NumWorkers
+	}
+	if config.FlushBytes <= 0 {
+		config.FlushBytes = defaultBulkFlushBytes
+	}
+	if config.FlushCount <= 0 {
+		config.FlushCount = defaultBulkFlushCount
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultBulkFlushInterval
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	} else if config.MaxRetries == 0 {
+		config.MaxRetries = defaultBulkMaxRetries
+	}
+	if config.RetryBackoff == nil {
+		config.RetryBackoff = defaultBulkRetryBackoff()
+	}
+	return config
+}
+
+// defaultBulkRetryBackoff waits 10ms, 100ms, 500ms and then 1s before each
+// retry.
+func defaultBulkRetryBackoff() func(attempt int) time.Duration {
+	ticks := []int{10, 100, 500, 1000}
+	backoff := retrier.NewSimpleBackoff(ticks...)
+	// The ticks are indexed from 0, and the attempts start at 1.
+	return func(attempt int) time.Duration {
+		return backoff(min(attempt, len(ticks)) - 1)
+	}
+}
+
+func (bi *bulkIndexer) Add(ctx context.Context, item BulkIndexerItem) error {
+	const op = errors.Op("v7.BulkIndexer.Add")
+
+	entry, err := newBulkIndexerEntry(item, bi.config.Index)
+	if err != nil {
+		return errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	bi.mu.RLock()
+	if bi.closed {
+		bi.mu.RUnlock()
+		return errors.E(op, ErrBulkIndexerClosed)
+	}
+	bi.inFlight.Add(1)
+	bi.mu.RUnlock()
+	defer bi.inFlight.Done()
+
+	select {
+	case bi.queue <- entry:
+		bi.numAdded.Add(1)
+		return nil
+	case <-bi.done:
+		return errors.E(op, ErrBulkIndexerClosed)
+	case <-ctx.Done():
+		return errors.E(op, ctx.Err())
+	}
+}
+
+// newBulkIndexerEntry encodes the item's document once, so the worker can
+// account for its size and the Bulk method can use it as is.
+func newBulkIndexerEntry(item BulkIndexerItem, defaultIndex string) (bulkIndexerEntry, error) {
+	if item.Document != nil && item.Action != BulkActionDelete {
+		document, err := compactDocument(item.Document)
+		if err != nil {
+			return bulkIndexerEntry{}, err
+		}
+		item.Document = document
+	}
+
+	var b bytes.Buffer
+	err := writeBulkItem(&b, item.BulkItem, defaultIndex)
+	if err != nil {
+		return bulkIndexerEntry{}, err
+	}
+
+	return bulkIndexerEntry{item: item, size: b.Len()}, nil
+}
+
+func (bi *bulkIndexer) Close(ctx context.Context) error {
+	const op = errors.Op("v7.BulkIndexer.Close")
+
+	bi.mu.Lock()
+	if !bi.closed {
+		bi.closed = true
+		close(bi.done)
+		go func() {
+			bi.inFlight.Wait()
+			close(bi.queue)
+		}()
+	}
+	bi.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		bi.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		bi.cancel()
+		return nil
+	case <-ctx.Done():
+		bi.cancel()
+		return errors.E(op, ctx.Err())
+	}
+}
+
+func (bi *bulkIndexer) Stats() BulkIndexerStats {
+	return BulkIndexerStats{
+		NumAdded:    bi.numAdded.Load(),
+		NumFlushed:  bi.numFlushed.Load(),
+		NumFailed:   bi.numFailed.Load(),
+		NumRetried:  bi.numRetried.Load(),
+		NumRequests: bi.numRequests.Load(),
+	}
+}
+
+func (bi *bulkIndexer) work() {
+	defer bi.wg.Done()
+
+	ctx := bi.ctx
+	ticker := time.NewTicker(bi.config.FlushInterval)
+	defer ticker.Stop()
+
+	var (
+		entries []bulkIndexerEntry
+		size    int
+	)
+
+	flush := func() {
+		if len(entries) == 0 {
+			return
+		}
+		bi.flush(ctx, entries)
+		entries = nil
+		size = 0
+	}
+
+	for {
+		select {
+		case entry, ok := <-bi.queue:
+			if !ok {
+				flush()
+				return
+			}
+			entries = append(entries, entry)
+			size += entry.size
+			if len(entries) >= bi.config.FlushCount || size >= bi.config.FlushBytes {
+				flush()
+				ticker.Reset(bi.config.FlushInterval)
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (bi *bulkIndexer) flush(ctx context.Context, entries []bulkIndexerEntry) {
+	for attempt := 0; len(entries) > 0; attempt++ {
+		if attempt > 0 {
+			bi.numRetried.Add(uint64(len(entries)))
+			timer := time.NewTimer(bi.config.RetryBackoff(attempt))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				for _, entry := range entries {
+					bi.fail(ctx, entry, BulkResponseItem{}, ctx.Err())
+				}
+				return
+			}
+		}
+
+		items := make([]BulkItem, 0, len(entries))
+		for _, entry := range entries {
+			items = append(items, entry.item.BulkItem)
+		}
+
+		bi.numRequests.Add(1)
+		response, err := bi.client.Bulk(ctx, BulkConfig{
+			Index:   bi.config.Index,
+			Items:   items,
+			Refresh: bi.config.Refresh,
+		})
+		if err != nil {
+			if attempt < bi.config.MaxRetries && IsRetryable(err) {
+				continue
+			}
+			bi.failAll(ctx, entries, err)
+			return
+		}
+
+		if len(response.Items) != len(entries) {
+			bi.failAll(ctx, entries, errors.E(
+				"unexpected number of items in bulk response",
+				ErrCodeUnexpectedResponse,
+				errors.KV("expected", len(entries)),
+				errors.KV("given", len(response.Items)),
+			))
+			return
+		}
+
+		var rejected []bulkIndexerEntry
+		for i, entry := range entries {
+			responseItem := response.Items[i]
+			switch {
+			case responseItem.Status == http.StatusTooManyRequests && attempt < bi.config.MaxRetries:
+				rejected = append(rejected, entry)
+			case responseItem.Error != nil || responseItem.Status >= http.StatusMultipleChoices:
+				bi.fail(ctx, entry, responseItem, getBulkItemError(responseItem))
+			default:
+				bi.numFlushed.Add(1)
+				if entry.item.OnSuccess != nil {
+					entry.item.OnSuccess(ctx, entry.item.BulkItem, responseItem)
+				}
+			}
+		}
+		entries = rejected
+	}
+}
+
+func (bi *bulkIndexer) failAll(ctx context.Context, entries []bulkIndexerEntry, err error) {
+	if bi.config.OnError != nil {
+		bi.config.OnError(ctx, err)
+	}
+	for _, entry := range entries {
+		bi.fail(ctx, entry, BulkResponseItem{}, err)
+	}
+}
+
+func (bi *bulkIndexer) fail(
+	ctx context.Context,
+	entry bulkIndexerEntry,
+	responseItem BulkResponseItem,
+	err error,
+) {
+	bi.numFailed.Add(1)
+	if entry.item.OnFailure != nil {
+		entry.item.OnFailure(ctx, entry.item.BulkItem, responseItem, err)
+	}
+}
+
+func getBulkItemError(item BulkResponseItem) error {
+	var err error = errors.New("bulk item failed")
+	if item.Error != nil {
+		err = *item.Error
+	}
+
+	code := ErrCodeBadGateway
+	switch item.Status {
+	case http.StatusBadRequest:
+		code = ErrCodeBadRequest
+	case http.StatusNotFound:
+		code = ErrCodeNotFound
+	case http.StatusConflict:
+		code = ErrCodeConflict
+	}
+
+	return errors.E(err, code, errors.KV("status", item.Status))
+}
@@ -0,0 +1,309 @@
+package v7
+
+import (
+	"context"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Bulk(t *testing.T) {
+	t.Parallel()
+
+	server := new(mockTransport)
+	server.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_bulk?refresh=true",
+		`{"index":{"_id":"id-1"}}
+{"Name":"John"}
+{"update":{"_id":"id-2","_index":"index2","retry_on_conflict":2}}
+{"doc":{"Name":"Mary"}}
+{"delete":{"_id":"id-3"}}
+`,
+	).Once().Return(
+		`{"took":30,"errors":true,"items":[{"index":{"_index":"index1","_id":"id-1","_version":1,"result":"created","status":201,"_seq_no":0,"_primary_term":1}},{"update":{"_index":"index2","_id":"id-2","status":404,"error":{"type":"document_missing_exception","reason":"[_doc][id-2]: document missing"}}},{"delete":{"_index":"index1","_id":"id-3","_version":2,"result":"deleted","status":200,"_seq_no":1,"_primary_term":1}}]}`,
+		200,
+		nil,
+	)
+
+	client := mustNewClientTest(server)
+	response, err := client.Bulk(context.Background(), BulkConfig{
+		Index:   "index1",
+		Refresh: RefreshTrue,
+		Items: []BulkItem{
+			{Action: BulkActionIndex, ID: "id-1", Document: map[string]string{"Name": "John"}},
+			{Action: BulkActionUpdate, Index: "index2", ID: "id-2", Document: []byte("{\n\"doc\": {\"Name\": \"Mary\"}\n}"), RetryOnConflict: 2},
+			{Action: BulkActionDelete, ID: "id-3"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, BulkResponse{
+		Took:   30,
+		Errors: true,
+		Items: []BulkResponseItem{
+			{Action: BulkActionIndex, ID: "id-1", Index: "index1", Version: 1, PrimaryTerm: 1, Result: "created", Status: 201},
+			{
+				Action: BulkActionUpdate,
+				ID:     "id-2",
+				Index:  "index2",
+				Status: 404,
+				Error:  &BulkItemError{Type: "document_missing_exception", Reason: "[_doc][id-2]: document missing"},
+			},
+			{Action: BulkActionDelete, ID: "id-3", Index: "index1", Version: 2, SeqNo: 1, PrimaryTerm: 1, Result: "deleted", Status: 200},
+		},
+	}, response)
+}
+
+type bulkClientStub struct {
+	Client
+	mu    sync.Mutex
+	calls [][]string
+	bulk  func(call int, config BulkConfig) (BulkResponse, error)
+}
+
+func (s *bulkClientStub) Bulk(_ context.Context, config BulkConfig) (BulkResponse, error) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(config.Items))
+	for _, item := range config.Items {
+		ids = append(ids, item.ID)
+	}
+	s.calls = append(s.calls, ids)
+	call := len(s.calls)
+	s.mu.Unlock()
+
+	return s.bulk(call, config)
+}
+
+func Test_BulkIndexer(t *testing.T) {
+	t.Parallel()
+
+	client := &bulkClientStub{
+		bulk: func(call int, config BulkConfig) (BulkResponse, error) {
+			response := BulkResponse{}
+			for _, item := range config.Items {
+				status := 201
+				switch {
+				case item.ID == "rejected" && call == 1:
+					status = 429
+				case item.ID == "invalid":
+					status = 400
+				}
+				response.Items = append(response.Items, BulkResponseItem{
+					Action: item.Action,
+					ID:     item.ID,
+					Index:  config.Index,
+					Status: status,
+				})
+			}
+			return response, nil
+		},
+	}
+
+	indexer, err := NewBulkIndexer(client, BulkIndexerConfig{
+		Index:        "index1",
+		NumWorkers:   1,
+		FlushCount:   3,
+		RetryBackoff: func(int) time.Duration { return time.Millisecond },
+	})
+	assert.NoError(t, err)
+
+	var (
+		mu        sync.Mutex
+		succeeded []string
+		failed    []string
+	)
+	for _, id := range []string{"ok-1", "rejected", "invalid", "ok-2"} {
+		err := indexer.Add(context.Background(), BulkIndexerItem{
+			BulkItem: BulkItem{
+				Action:   BulkActionIndex,
+				ID:       id,
+				Document: map[string]string{"ID": id},
+			},
+			OnSuccess: func(_ context.Context, item BulkItem, _ BulkResponseItem) {
+				mu.Lock()
+				defer mu.Unlock()
+				succeeded = append(succeeded, item.ID)
+			},
+			OnFailure: func(_ context.Context, item BulkItem, _ BulkResponseItem, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failed = append(failed, item.ID)
+				assert.Equal(t, ErrCodeBadRequest, errors.GetCode(err))
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, indexer.Close(context.Background()))
+	assert.Equal(t, [][]string{{"ok-1", "rejected", "invalid"}, {"rejected"}, {"ok-2"}}, client.calls)
+	assert.Equal(t, []string{"ok-1", "rejected", "ok-2"}, succeeded)
+	assert.Equal(t, []string{"invalid"}, failed)
+	assert.Equal(t, BulkIndexerStats{
+		NumAdded:    4,
+		NumFlushed:  3,
+		NumFailed:   1,
+		NumRetried:  1,
+		NumRequests: 3,
+	}, indexer.Stats())
+
+	err = indexer.Add(context.Background(), BulkIndexerItem{
+		BulkItem: BulkItem{Action: BulkActionDelete, ID: "id-1"},
+	})
+	assert.EqualError(t, err, "v7.BulkIndexer.Add: bulk indexer is closed")
+}
+
+func Test_BulkIndexer_CloseWithFullQueue(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	client := &bulkClientStub{
+		bulk: func(call int, config BulkConfig) (BulkResponse, error) {
+			if call == 1 {
+				close(started)
+				<-release
+			}
+			response := BulkResponse{}
+			for _, item := range config.Items {
+				response.Items = append(response.Items, BulkResponseItem{ID: item.ID, Status: 201})
+			}
+			return response, nil
+		},
+	}
+
+	indexer, err := NewBulkIndexer(client, BulkIndexerConfig{
+		Index:      "index1",
+		NumWorkers: 1,
+		QueueSize:  1,
+		FlushCount: 1,
+	})
+	assert.NoError(t, err)
+
+	newItem := func(id string) BulkIndexerItem {
+		return BulkIndexerItem{BulkItem: BulkItem{Action: BulkActionDelete, ID: id}}
+	}
+	assert.NoError(t, indexer.Add(context.Background(), newItem("id-1")))
+	<-started
+	assert.NoError(t, indexer.Add(context.Background(), newItem("id-2")))
+
+	blockedAdd := make(chan error)
+	go func() {
+		blockedAdd <- indexer.Add(context.Background(), newItem("id-3"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = indexer.Close(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.EqualError(t, <-blockedAdd, "v7.BulkIndexer.Add: bulk indexer is closed")
+
+	close(release)
+	assert.NoError(t, indexer.Close(context.Background()))
+	assert.Equal(t, [][]string{{"id-1"}, {"id-2"}}, client.calls)
+}
+
+func Test_BulkIndexer_CloseAbandonsRetries(t *testing.T) {
+	t.Parallel()
+
+	client := &bulkClientStub{
+		bulk: func(_ int, config BulkConfig) (BulkResponse, error) {
+			response := BulkResponse{}
+			for _, item := range config.Items {
+				response.Items = append(response.Items, BulkResponseItem{ID: item.ID, Status: 429})
+			}
+			return response, nil
+		},
+	}
+
+	indexer, err := NewBulkIndexer(client, BulkIndexerConfig{
+		Index:        "index1",
+		NumWorkers:   1,
+		FlushCount:   1,
+		RetryBackoff: func(int) time.Duration { return time.Hour },
+	})
+	assert.NoError(t, err)
+
+	failed := make(chan error, 1)
+	err = indexer.Add(context.Background(), BulkIndexerItem{
+		BulkItem: BulkItem{Action: BulkActionDelete, ID: "id-1"},
+		OnFailure: func(_ context.Context, _ BulkItem, _ BulkResponseItem, err error) {
+			failed <- err
+		},
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = indexer.Close(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.True(t, errors.Is(<-failed, context.Canceled))
+	assert.Equal(t, uint64(1), indexer.Stats().NumFailed)
+}
+
+func Test_BulkIndexer_RetriesRequests(t *testing.T) {
+	t.Parallel()
+
+	client := &bulkClientStub{
+		bulk: func(call int, config BulkConfig) (BulkResponse, error) {
+			switch call {
+			case 1:
+				return BulkResponse{}, errors.E(&ElasticError{Status: 429}, ErrCodeTooManyRequests)
+			case 2:
+				return BulkResponse{}, &net.OpError{Op: "read", Err: syscall.ECONNRESET}
+			}
+			response := BulkResponse{}
+			for _, item := range config.Items {
+				response.Items = append(response.Items, BulkResponseItem{ID: item.ID, Status: 201})
+			}
+			return response, nil
+		},
+	}
+
+	var attempts []int
+	indexer, err := NewBulkIndexer(client, BulkIndexerConfig{
+		Index:      "index1",
+		NumWorkers: 1,
+		FlushCount: 1,
+		RetryBackoff: func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Millisecond
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, indexer.Add(context.Background(), BulkIndexerItem{
+		BulkItem: BulkItem{Action: BulkActionDelete, ID: "id-1"},
+	}))
+	assert.NoError(t, indexer.Close(context.Background()))
+
+	assert.Equal(t, []int{1, 2}, attempts)
+	assert.Equal(t, BulkIndexerStats{
+		NumAdded:    1,
+		NumFlushed:  1,
+		NumRetried:  2,
+		NumRequests: 3,
+	}, indexer.Stats())
+}
+
+func Test_defaultBulkRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	backoff := defaultBulkRetryBackoff()
+	var backoffs []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		backoffs = append(backoffs, backoff(attempt))
+	}
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		100 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		time.Second,
+		time.Second,
+	}, backoffs)
+}
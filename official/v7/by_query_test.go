@@ -0,0 +1,359 @@
+package v7
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/arquivei/foundationkit/ref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+type byQueryFilterMust struct {
+	Names []string `es:"Name"`
+}
+
+func Test_DeleteByQuery(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		config            DeleteByQueryConfig
+		transport         *mockTransport
+		expectedResponse  ByQueryResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: DeleteByQueryConfig{
+				Indexes: []string{"index1"},
+				Filter: Filter{
+					Must: byQueryFilterMust{Names: []string{"John"}},
+				},
+				Conflicts:         ConflictsProceed,
+				Slices:            SlicesAuto,
+				RequestsPerSecond: ref.Of(0.5),
+				Refresh:           true,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_delete_by_query?allow_no_indices=false&conflicts=proceed&ignore_unavailable=false&refresh=true&requests_per_second=0.5&slices=auto",
+					`{"query":{"terms":{"Name":["John"]}}}`,
+				).Once().Return(
+					`{"took":147,"timed_out":false,"total":3,"deleted":2,"batches":1,"version_conflicts":1,"noops":0,"failures":[{"index":"index1","id":"id-3","status":409,"cause":{"type":"version_conflict_engine_exception","reason":"version conflict"}}]}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: ByQueryResponse{
+				Took:             147,
+				Total:            3,
+				Deleted:          2,
+				Batches:          1,
+				VersionConflicts: 1,
+				Failures: []ByQueryFailure{
+					{
+						Index:  "index1",
+						ID:     "id-3",
+						Status: 409,
+						Type:   "version_conflict_engine_exception",
+						Reason: "version conflict",
+					},
+				},
+			},
+		},
+		{
+			name: "async",
+			config: DeleteByQueryConfig{
+				Indexes:  []string{"index1"},
+				MatchAll: true,
+				Async:    true,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_delete_by_query?allow_no_indices=false&ignore_unavailable=false&wait_for_completion=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(`{"task":"node-1:42"}`, 200, nil)
+				return server
+			}(),
+			expectedResponse: ByQueryResponse{TaskID: "node-1:42"},
+		},
+		{
+			name:              "missing indexes",
+			config:            DeleteByQueryConfig{},
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.DeleteByQuery: doDeleteByQuery: [Indexes] is required",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+		{
+			name:              "empty filter",
+			config:            DeleteByQueryConfig{Indexes: []string{"index1"}},
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.DeleteByQuery: doDeleteByQuery: getByQueryBody: [Filter] is required",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+		{
+			name: "aborted on version conflict",
+			config: DeleteByQueryConfig{
+				Indexes: []string{"index1"},
+				Filter: Filter{
+					Must: byQueryFilterMust{Names: []string{"John"}},
+				},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_delete_by_query?allow_no_indices=false&ignore_unavailable=false",
+					`{"query":{"terms":{"Name":["John"]}}}`,
+				).Once().Return(
+					`{"took":12,"timed_out":false,"total":3,"deleted":1,"batches":1,"version_conflicts":1,"noops":0,"failures":[{"index":"index1","id":"id-3","status":409,"cause":{"type":"version_conflict_engine_exception","reason":"version conflict"}}]}`,
+					409,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.DeleteByQuery: doDeleteByQuery: [409 Conflict] 1 failures: version_conflict_engine_exception: version conflict",
+			expectedErrorCode: ErrCodeConflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := client.DeleteByQuery(context.Background(), test.config)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UpdateByQuery(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		config            UpdateByQueryConfig
+		transport         *mockTransport
+		expectedResponse  ByQueryResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: UpdateByQueryConfig{
+				Indexes: []string{"index1"},
+				Filter: Filter{
+					Must: byQueryFilterMust{Names: []string{"John"}},
+				},
+				Script:    querybuilders.NewScript("ctx._source.Active = params.active").Param("active", false),
+				Conflicts: ConflictsProceed,
+				Slices:    2,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_update_by_query?allow_no_indices=false&conflicts=proceed&ignore_unavailable=false&slices=2",
+					`{"query":{"terms":{"Name":["John"]}},"script":{"params":{"active":false},"source":"ctx._source.Active = params.active"}}`,
+				).Once().Return(
+					`{"took":20,"timed_out":false,"total":2,"updated":2,"batches":1,"version_conflicts":0,"noops":0,"failures":[]}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: ByQueryResponse{
+				Took:    20,
+				Total:   2,
+				Updated: 2,
+				Batches: 1,
+			},
+		},
+		{
+			name: "index not found",
+			config: UpdateByQueryConfig{
+				Indexes:  []string{"index1"},
+				MatchAll: true,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_update_by_query?allow_no_indices=false&ignore_unavailable=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index1]"}],"type":"index_not_found_exception","reason":"no such index [index1]"},"status":404}`,
+					404,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.UpdateByQuery: doUpdateByQuery: [404 Not Found] index_not_found_exception: no such index [index1]: no such index [index1]",
+			expectedErrorCode: ErrCodeNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := client.UpdateByQuery(context.Background(), test.config)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_UpdateByQuery_ByQueryError(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_update_by_query?allow_no_indices=false&ignore_unavailable=false",
+		`{"query":{"match_all":{}}}`,
+	).Once().Return(
+		`{"took":5,"timed_out":false,"total":2,"updated":1,"batches":1,"version_conflicts":1,"noops":0,"failures":[{"index":"index1","id":"id-2","status":409,"cause":{"type":"version_conflict_engine_exception","reason":"version conflict"}}]}`,
+		409,
+		nil,
+	)
+	defer transport.AssertExpectations(t)
+
+	client := mustNewClientTest(transport)
+	_, err := client.UpdateByQuery(context.Background(), UpdateByQueryConfig{
+		Indexes:  []string{"index1"},
+		MatchAll: true,
+	})
+
+	var byQueryError *ByQueryError
+	require.True(t, stderrors.As(err, &byQueryError), "unexpected error: %v", err)
+	assert.Equal(t, &ByQueryError{
+		Status: 409,
+		Response: ByQueryResponse{
+			Took:             5,
+			Total:            2,
+			Updated:          1,
+			Batches:          1,
+			VersionConflicts: 1,
+			Failures: []ByQueryFailure{
+				{
+					Index:  "index1",
+					ID:     "id-2",
+					Status: 409,
+					Type:   "version_conflict_engine_exception",
+					Reason: "version conflict",
+				},
+			},
+		},
+	}, byQueryError)
+	assert.Equal(t, ErrCodeConflict, errors.GetCode(err))
+}
+
+func Test_WaitForTask(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		transport         *mockTransport
+		expectedResponse  TaskResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_tasks/node-1:42",
+					"",
+				).Once().Return(
+					`{"completed":false,"task":{"node":"node-1","id":42,"status":{"total":2,"deleted":1,"batches":1}}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_tasks/node-1:42",
+					"",
+				).Once().Return(
+					`{"completed":true,"task":{"node":"node-1","id":42,"status":{"total":2,"deleted":2,"batches":1}},"response":{"took":30,"total":2,"deleted":2,"batches":1,"failures":[]}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: TaskResponse{
+				ID:        "node-1:42",
+				Completed: true,
+				Status:    ByQueryResponse{Total: 2, Deleted: 2, Batches: 1},
+				Response:  ByQueryResponse{Took: 30, Total: 2, Deleted: 2, Batches: 1},
+			},
+		},
+		{
+			name: "task failed",
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_tasks/node-1:42",
+					"",
+				).Once().Return(
+					`{"completed":true,"task":{"node":"node-1","id":42,"status":{"total":2}},"error":{"type":"search_phase_execution_exception","reason":"all shards failed"}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: TaskResponse{
+				ID:        "node-1:42",
+				Completed: true,
+				Status:    ByQueryResponse{Total: 2},
+				Error: &TaskError{
+					Type:   "search_phase_execution_exception",
+					Reason: "all shards failed",
+				},
+			},
+			expectedError:     "v7.WaitForTask: search_phase_execution_exception: all shards failed",
+			expectedErrorCode: ErrCodeBadGateway,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := WaitForTask(context.Background(), client, "node-1:42", time.Millisecond)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
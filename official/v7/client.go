@@ -2,88 +2,74 @@ package v7
 
 import (
 	"context"
-	"crypto/tls"
 	"crypto/x509"
-	"net/http"
+	"time"
 
-	"github.com/arquivei/elasticutil/official/v7/internal/retrier"
 	es "github.com/elastic/go-elasticsearch/v7"
 )
 
 // Client represents the Elasticsearch's client of the official lib.
 type Client interface {
 	Search(context.Context, SearchConfig) (SearchResponse, error)
+	Get(context.Context, GetConfig) (GetResponse, error)
+	MGet(context.Context, MGetConfig) (MGetResponse, error)
+	Index(context.Context, IndexConfig) (WriteResponse, error)
+	Update(context.Context, UpdateConfig) (WriteResponse, error)
+	Delete(context.Context, DeleteConfig) (WriteResponse, error)
+	Bulk(context.Context, BulkConfig) (BulkResponse, error)
+	Count(context.Context, CountConfig) (CountResponse, error)
+	OpenPointInTime(context.Context, OpenPointInTimeConfig) (string, error)
+	ClosePointInTime(ctx context.Context, id string) error
+	Scroll(ctx context.Context, config SearchConfig, keepAlive time.Duration) (ScrollCursor, error)
+	MultiSearch(context.Context, []SearchConfig) (MultiSearchResponse, error)
+	DeleteByQuery(context.Context, DeleteByQueryConfig) (ByQueryResponse, error)
+	UpdateByQuery(context.Context, UpdateByQueryConfig) (ByQueryResponse, error)
+	GetTask(ctx context.Context, taskID string) (TaskResponse, error)
 }
 
 type esClient struct {
-	client *es.Client
+	client         *es.Client
+	retryPolicy    RetryPolicy
+	paginatorCodec *PaginatorCodec
 }
 
 // NewClient returns a new Client using the @urls.
+//
+// Deprecated: use NewClientWithOptions.
 func NewClient(urls ...string) (Client, error) {
-	client, err := es.NewClient(es.Config{
-		Addresses:    urls,
-		RetryBackoff: retrier.NewSimpleBackoff(10, 100),
-		Transport: &http.Transport{
-			DisableCompression: false,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return &esClient{
-		client: client,
-	}, nil
+	return NewClientWithOptions(urls)
 }
 
 // NewClientWithAuth returns a new Client using the @urls and some auth parameters.
-// If @certPem is nil, it will try to create a client without authentication.
+// If @certPem is nil, it will try to create a client without verifying the
+// server certificate.
+//
+// Deprecated: use NewClientWithOptions with WithBasicAuth and WithCACert, or
+// WithInsecureSkipVerify, which must be explicitly set.
 func NewClientWithAuth(urls []string, certPem []byte, username, password string) (Client, error) {
-	client, err := es.NewClient(getElasticAuthConfig(urls, certPem, username, password))
-	if err != nil {
-		return nil, err
-	}
-
-	return &esClient{
-		client: client,
-	}, nil
+	return NewClientWithOptions(urls, getAuthOptions(certPem, username, password)...)
 }
 
-func getElasticAuthConfig(urls []string, certPem []byte, username, password string) es.Config {
-	esConfig := es.Config{
-		Addresses:    urls,
-		Username:     username,
-		Password:     password,
-		RetryBackoff: retrier.NewSimpleBackoff(10, 100),
+func getAuthOptions(certPem []byte, username, password string) []Option {
+	options := []Option{
+		WithBasicAuth(username, password),
 	}
 
 	if len(certPem) == 0 {
-		esConfig.Transport = &http.Transport{
-			DisableCompression: false,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}
-
-		return esConfig
-	}
-
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(certPem)
-
-	esConfig.Transport = &http.Transport{
-		DisableCompression: false,
-		TLSClientConfig: &tls.Config{
-			RootCAs: caCertPool,
-		},
+		return append(options, WithInsecureSkipVerify())
 	}
 
-	return esConfig
+	// Unlike WithCACert, an unparsable @certPem is not an error here, as it
+	// was not before these constructors were deprecated.
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPem)
+	return append(options, WithCAPool(pool))
 }
 
 // MustNewClient returns a new Client using the @urls. It panics
 // instead of returning an error.
+//
+// Deprecated: use MustNewClientWithOptions.
 func MustNewClient(urls ...string) Client {
 	client, err := NewClient(urls...)
 	if err != nil {
@@ -95,6 +81,8 @@ func MustNewClient(urls ...string) Client {
 
 // MustNewClientWithAuth returns a new Client using the @urls and some auth parameters.
 // It panics instead of returning an error.
+//
+// Deprecated: use MustNewClientWithOptions.
 func MustNewClientWithAuth(urls []string, certPem []byte, username, password string) Client {
 	client, err := NewClientWithAuth(urls, certPem, username, password)
 	if err != nil {
@@ -0,0 +1,383 @@
+package v7
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// tracingQueryMaxLength is how many runes of the query are added to a span,
+// unless the full query is captured.
+const tracingQueryMaxLength = 1000
+
+// instrumentedClient wraps a Client, reporting each of its operations to
+// the instrumentation and applying its LogConfig.
+type instrumentedClient struct {
+	next            Client
+	instrumentation instrumentation
+}
+
+type instrumentation struct {
+	metrics          Metrics
+	tracer           Tracer
+	captureFullQuery bool
+	redactStatement  bool
+	logConfig        LogConfig
+	slowLog          SlowLogConfig
+}
+
+func (i instrumentation) enabled() bool {
+	return i.metrics != nil || i.tracer != nil || i.logConfig != LogConfig{} || i.slowLog.enabled()
+}
+
+func instrument[T any](
+	ctx context.Context,
+	i instrumentation,
+	operation string,
+	indexes []string,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	ctx = withLogConfig(ctx, i.logConfig)
+	ctx, info := withCallInfo(ctx)
+	info.redactQuery = i.tracer != nil && i.redactStatement
+	request := MetricsRequest{
+		Operation: operation,
+		Indexes:   indexes,
+	}
+
+	var span Span
+	if i.tracer != nil {
+		ctx, span = i.tracer.Start(ctx, "elasticsearch."+operation)
+	}
+	if i.metrics != nil {
+		i.metrics.RequestStarted(ctx, request)
+	}
+	start := time.Now()
+
+	response, err := fn(ctx)
+
+	result := info.result(time.Since(start), err)
+	if i.metrics != nil {
+		i.metrics.RequestFinished(ctx, request, result)
+	}
+	if span != nil {
+		i.endSpan(span, request, result, info.getQuery())
+	}
+
+	return response, err
+}
+
+func (i instrumentation) endSpan(span Span, request MetricsRequest, result MetricsResult, query string) {
+	defer span.End()
+
+	attributes := []Attribute{
+		{Key: "db.system", Value: "elasticsearch"},
+		{Key: "db.operation", Value: request.Operation},
+	}
+	if len(request.Indexes) > 0 {
+		attributes = append(attributes, Attribute{Key: "db.elasticsearch.indexes", Value: strings.Join(request.Indexes, ",")})
+	}
+	if query != "" {
+		if !i.captureFullQuery || !span.IsRecording() {
+			query = truncate(query, tracingQueryMaxLength)
+		}
+		attributes = append(attributes, Attribute{Key: "db.statement", Value: query})
+	}
+	if result.StatusCode != 0 {
+		attributes = append(attributes, Attribute{Key: "http.status_code", Value: result.StatusCode})
+	}
+	if result.Took > 0 {
+		attributes = append(attributes, Attribute{Key: "db.elasticsearch.took_ms", Value: result.Took.Milliseconds()})
+	}
+	if result.Retries > 0 {
+		attributes = append(attributes, Attribute{Key: "db.elasticsearch.retries", Value: result.Retries})
+	}
+	span.SetAttributes(attributes...)
+
+	if result.Err != nil {
+		span.RecordError(result.Err)
+	}
+}
+
+func indexesOf(index string) []string {
+	if index == "" {
+		return nil
+	}
+	return []string{index}
+}
+
+func (c *instrumentedClient) Search(ctx context.Context, config SearchConfig) (SearchResponse, error) {
+	start := time.Now()
+	response, err := instrument(ctx, c.instrumentation, "Search", config.Indexes, func(ctx context.Context) (SearchResponse, error) {
+		return c.next.Search(ctx, config)
+	})
+	c.instrumentation.slowLog.checkSearch(ctx, config, response, time.Since(start), err)
+	return response, err
+}
+
+func (c *instrumentedClient) Get(ctx context.Context, config GetConfig) (GetResponse, error) {
+	return instrument(ctx, c.instrumentation, "Get", indexesOf(config.Index), func(ctx context.Context) (GetResponse, error) {
+		return c.next.Get(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) MGet(ctx context.Context, config MGetConfig) (MGetResponse, error) {
+	return instrument(ctx, c.instrumentation, "MGet", indexesOf(config.Index), func(ctx context.Context) (MGetResponse, error) {
+		return c.next.MGet(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) Index(ctx context.Context, config IndexConfig) (WriteResponse, error) {
+	return instrument(ctx, c.instrumentation, "Index", indexesOf(config.Index), func(ctx context.Context) (WriteResponse, error) {
+		return c.next.Index(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) Update(ctx context.Context, config UpdateConfig) (WriteResponse, error) {
+	return instrument(ctx, c.instrumentation, "Update", indexesOf(config.Index), func(ctx context.Context) (WriteResponse, error) {
+		return c.next.Update(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) Delete(ctx context.Context, config DeleteConfig) (WriteResponse, error) {
+	return instrument(ctx, c.instrumentation, "Delete", indexesOf(config.Index), func(ctx context.Context) (WriteResponse, error) {
+		return c.next.Delete(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) Bulk(ctx context.Context, config BulkConfig) (BulkResponse, error) {
+	return instrument(ctx, c.instrumentation, "Bulk", indexesOf(config.Index), func(ctx context.Context) (BulkResponse, error) {
+		return c.next.Bulk(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) Count(ctx context.Context, config CountConfig) (CountResponse, error) {
+	return instrument(ctx, c.instrumentation, "Count", config.Indexes, func(ctx context.Context) (CountResponse, error) {
+		return c.next.Count(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) OpenPointInTime(ctx context.Context, config OpenPointInTimeConfig) (string, error) {
+	return instrument(ctx, c.instrumentation, "OpenPointInTime", config.Indexes, func(ctx context.Context) (string, error) {
+		return c.next.OpenPointInTime(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) ClosePointInTime(ctx context.Context, id string) error {
+	_, err := instrument(ctx, c.instrumentation, "ClosePointInTime", nil, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, c.next.ClosePointInTime(ctx, id)
+	})
+	return err
+}
+
+func (c *instrumentedClient) Scroll(ctx context.Context, config SearchConfig, keepAlive time.Duration) (ScrollCursor, error) {
+	start := time.Now()
+	cursor, err := instrument(ctx, c.instrumentation, "Scroll", config.Indexes, func(ctx context.Context) (ScrollCursor, error) {
+		return c.next.Scroll(ctx, config, keepAlive)
+	})
+	if err != nil {
+		c.instrumentation.slowLog.checkSearch(ctx, config, SearchResponse{}, time.Since(start), err)
+		return nil, err
+	}
+
+	// The first page is fetched by Scroll but returned by the first Next,
+	// so it is checked there, with the latency of Scroll.
+	return &instrumentedScrollCursor{
+		next:             cursor,
+		config:           config,
+		instrumentation:  c.instrumentation,
+		firstPageLatency: time.Since(start),
+	}, nil
+}
+
+func (c *instrumentedClient) MultiSearch(ctx context.Context, configs []SearchConfig) (MultiSearchResponse, error) {
+	start := time.Now()
+	response, err := instrument(ctx, c.instrumentation, "MultiSearch", getMultiSearchIndexes(configs), func(ctx context.Context) (MultiSearchResponse, error) {
+		return c.next.MultiSearch(ctx, configs)
+	})
+	c.instrumentation.slowLog.checkMultiSearch(ctx, configs, response, time.Since(start), err)
+	return response, err
+}
+
+func (c *instrumentedClient) DeleteByQuery(ctx context.Context, config DeleteByQueryConfig) (ByQueryResponse, error) {
+	return instrument(ctx, c.instrumentation, "DeleteByQuery", config.Indexes, func(ctx context.Context) (ByQueryResponse, error) {
+		return c.next.DeleteByQuery(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) UpdateByQuery(ctx context.Context, config UpdateByQueryConfig) (ByQueryResponse, error) {
+	return instrument(ctx, c.instrumentation, "UpdateByQuery", config.Indexes, func(ctx context.Context) (ByQueryResponse, error) {
+		return c.next.UpdateByQuery(ctx, config)
+	})
+}
+
+func (c *instrumentedClient) GetTask(ctx context.Context, taskID string) (TaskResponse, error) {
+	return instrument(ctx, c.instrumentation, "GetTask", nil, func(ctx context.Context) (TaskResponse, error) {
+		return c.next.GetTask(ctx, taskID)
+	})
+}
+
+type instrumentedScrollCursor struct {
+	next             ScrollCursor
+	config           SearchConfig
+	instrumentation  instrumentation
+	firstPageLatency time.Duration
+}
+
+func (s *instrumentedScrollCursor) Next(ctx context.Context) (SearchResponse, error) {
+	start := time.Now()
+	response, err := instrument(ctx, s.instrumentation, "ScrollCursor.Next", s.config.Indexes, func(ctx context.Context) (SearchResponse, error) {
+		return s.next.Next(ctx)
+	})
+
+	latency := time.Since(start) + s.firstPageLatency
+	s.firstPageLatency = 0
+	if errors.Is(err, ErrEndOfScroll) {
+		err = nil
+	}
+	s.instrumentation.slowLog.checkSearch(ctx, s.config, response, latency, err)
+
+	return response, err
+}
+
+func (s *instrumentedScrollCursor) Close(ctx context.Context) error {
+	_, err := instrument(ctx, s.instrumentation, "ScrollCursor.Close", s.config.Indexes, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.next.Close(ctx)
+	})
+	return err
+}
+
+type callInfoKey struct{}
+
+// callInfo holds the measurements of a single operation while it runs. It
+// is stored in the operation's context so the transport and the parsers can
+// fill it.
+type callInfo struct {
+	mu            sync.Mutex
+	took          time.Duration
+	statusCode    int
+	query         string
+	redactQuery   bool
+	shardsFailed  int
+	retries       int
+	responseBytes int64
+}
+
+func withCallInfo(ctx context.Context) (context.Context, *callInfo) {
+	info := &callInfo{}
+	return context.WithValue(ctx, callInfoKey{}, info), info
+}
+
+func getCallInfo(ctx context.Context) *callInfo {
+	info, _ := ctx.Value(callInfoKey{}).(*callInfo)
+	return info
+}
+
+func (i *callInfo) update(f func(*callInfo)) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	f(i)
+}
+
+// redactsQuery tells whether the query recorded for the span must be
+// redacted.
+func (i *callInfo) redactsQuery() bool {
+	if i == nil {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.redactQuery
+}
+
+func (i *callInfo) getQuery() string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.query
+}
+
+func (i *callInfo) result(latency time.Duration, err error) MetricsResult {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return MetricsResult{
+		Latency:       latency,
+		Took:          i.took,
+		StatusCode:    i.statusCode,
+		ShardsFailed:  i.shardsFailed,
+		Retries:       i.retries,
+		ResponseBytes: i.responseBytes,
+		Err:           err,
+	}
+}
+
+func recordTook(ctx context.Context, took int) {
+	getCallInfo(ctx).update(func(i *callInfo) {
+		i.took = time.Duration(took) * time.Millisecond
+	})
+}
+
+func recordQuery(ctx context.Context, query string) {
+	getCallInfo(ctx).update(func(i *callInfo) {
+		i.query = query
+	})
+}
+
+func recordShards(ctx context.Context, shards *ShardsInfo) {
+	if shards == nil {
+		return
+	}
+	getCallInfo(ctx).update(func(i *callInfo) {
+		i.shardsFailed += shards.Failed
+	})
+}
+
+func recordRetry(ctx context.Context) {
+	getCallInfo(ctx).update(func(i *callInfo) {
+		i.retries++
+	})
+}
+
+// callInfoTransport records the status code and the size of the responses
+// of the requests made on behalf of an operation.
+type callInfoTransport struct {
+	next http.RoundTripper
+}
+
+func (t *callInfoTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(r)
+
+	info := getCallInfo(r.Context())
+	// The product check made by the elasticsearch client on its first
+	// request is not part of the operation.
+	if info == nil || err != nil || r.URL.Path == "/" {
+		return response, err
+	}
+
+	info.update(func(i *callInfo) {
+		i.statusCode = response.StatusCode
+	})
+	response.Body = &countingBody{ReadCloser: response.Body, info: info}
+
+	return response, nil
+}
+
+type countingBody struct {
+	io.ReadCloser
+	info *callInfo
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.info.update(func(i *callInfo) {
+		i.responseBytes += int64(n)
+	})
+	return n, err
+}
@@ -2,23 +2,192 @@ package v7
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
 
-type mockClient struct {
+// MockClient is a Client backed by testify's mock.Mock. Expectations are
+// set with On using the method name and the config the method receives, so
+// any mock.Mock feature can be used: the context is not passed.
+//
+// Besides the config itself, the argument of an expectation can be one of
+// the matchers of this package, such as MatchAny, MatchIndexes,
+// MatchFilterContains and MatchConfig, so a test does not break when the
+// code under test sets a field the test does not care about. Use InSequence
+// to return different responses to consecutive calls and MockCalls to
+// inspect the configs the mock received.
+type MockClient struct {
 	mock.Mock
 }
 
-func (m *mockClient) Search(_ context.Context, sc SearchConfig) (SearchResponse, error) {
+var _ Client = (*MockClient)(nil)
+
+// cleanupTestingT is a mock.TestingT that runs functions after the test,
+// such as *testing.T.
+type cleanupTestingT interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewMockClient returns a MockClient that asserts that all its expectations
+// were met when the @t test finishes.
+func NewMockClient(t cleanupTestingT) *MockClient {
+	m := &MockClient{}
+	m.Test(t)
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+func (m *MockClient) Search(_ context.Context, sc SearchConfig) (SearchResponse, error) {
 	args := m.Called(sc)
 	return args.Get(0).(SearchResponse), args.Error(1)
 }
 
+func (m *MockClient) Get(_ context.Context, gc GetConfig) (GetResponse, error) {
+	args := m.Called(gc)
+	return args.Get(0).(GetResponse), args.Error(1)
+}
+
+func (m *MockClient) MGet(_ context.Context, mc MGetConfig) (MGetResponse, error) {
+	args := m.Called(mc)
+	return args.Get(0).(MGetResponse), args.Error(1)
+}
+
+func (m *MockClient) Index(_ context.Context, ic IndexConfig) (WriteResponse, error) {
+	args := m.Called(ic)
+	return args.Get(0).(WriteResponse), args.Error(1)
+}
+
+func (m *MockClient) Update(_ context.Context, uc UpdateConfig) (WriteResponse, error) {
+	args := m.Called(uc)
+	return args.Get(0).(WriteResponse), args.Error(1)
+}
+
+func (m *MockClient) Delete(_ context.Context, dc DeleteConfig) (WriteResponse, error) {
+	args := m.Called(dc)
+	return args.Get(0).(WriteResponse), args.Error(1)
+}
+
+func (m *MockClient) Bulk(_ context.Context, bc BulkConfig) (BulkResponse, error) {
+	args := m.Called(bc)
+	return args.Get(0).(BulkResponse), args.Error(1)
+}
+
+func (m *MockClient) Count(_ context.Context, cc CountConfig) (CountResponse, error) {
+	args := m.Called(cc)
+	return args.Get(0).(CountResponse), args.Error(1)
+}
+
+func (m *MockClient) OpenPointInTime(_ context.Context, opc OpenPointInTimeConfig) (string, error) {
+	args := m.Called(opc)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockClient) ClosePointInTime(_ context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockClient) Scroll(_ context.Context, sc SearchConfig, keepAlive time.Duration) (ScrollCursor, error) {
+	args := m.Called(sc, keepAlive)
+	cursor, _ := args.Get(0).(ScrollCursor)
+	return cursor, args.Error(1)
+}
+
+// MockScrollCursor is a ScrollCursor backed by testify's mock.Mock, to be
+// returned by a MockClient's Scroll. Expectations are set with On using the
+// method name and no arguments, as the context is not passed.
+//
+//	cursor := v7.NewMockScrollCursor(t)
+//	v7.InSequence(
+//		cursor.On("Next").Return(firstPage, nil),
+//		cursor.On("Next").Return(v7.SearchResponse{}, v7.ErrEndOfScroll),
+//	)
+//	cursor.On("Close").Return(nil)
+//	m.On("Scroll", v7.MatchAny(), time.Minute).Return(cursor, nil)
+type MockScrollCursor struct {
+	mock.Mock
+}
+
+var _ ScrollCursor = (*MockScrollCursor)(nil)
+
+// NewMockScrollCursor returns a MockScrollCursor that asserts that all its
+// expectations were met when the @t test finishes.
+func NewMockScrollCursor(t cleanupTestingT) *MockScrollCursor {
+	m := &MockScrollCursor{}
+	m.Test(t)
+	t.Cleanup(func() { m.AssertExpectations(t) })
+	return m
+}
+
+func (m *MockScrollCursor) Next(_ context.Context) (SearchResponse, error) {
+	args := m.Called()
+	return args.Get(0).(SearchResponse), args.Error(1)
+}
+
+func (m *MockScrollCursor) Close(_ context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockClient) MultiSearch(_ context.Context, scs []SearchConfig) (MultiSearchResponse, error) {
+	args := m.Called(scs)
+	return args.Get(0).(MultiSearchResponse), args.Error(1)
+}
+
+func (m *MockClient) DeleteByQuery(_ context.Context, dc DeleteByQueryConfig) (ByQueryResponse, error) {
+	args := m.Called(dc)
+	return args.Get(0).(ByQueryResponse), args.Error(1)
+}
+
+func (m *MockClient) UpdateByQuery(_ context.Context, uc UpdateByQueryConfig) (ByQueryResponse, error) {
+	args := m.Called(uc)
+	return args.Get(0).(ByQueryResponse), args.Error(1)
+}
+
+func (m *MockClient) GetTask(_ context.Context, taskID string) (TaskResponse, error) {
+	args := m.Called(taskID)
+	return args.Get(0).(TaskResponse), args.Error(1)
+}
+
 // MustNewClientMock returns a mocked Client that uses Search method and returns @expectedResponse
-// and @expectedError for the giving @input.
-func MustNewClientMockSearch(input SearchConfig, expectedResponse SearchResponse, expectedError error) Client {
-	m := mockClient{}
+// and @expectedError for the giving @input. The @input may also be a matcher,
+// such as MatchAny or MatchIndexes.
+func MustNewClientMockSearch(input interface{}, expectedResponse SearchResponse, expectedError error) Client {
+	m := MockClient{}
 	m.On("Search", input).Return(expectedResponse, expectedError)
 	return &m
 }
+
+// InSequence makes the @calls, which must match the same arguments, answer
+// consecutive calls in order: each one is used once, except for the last,
+// which answers all the calls after it.
+//
+//	v7.InSequence(
+//		m.On("Search", v7.MatchAny()).Return(firstPage, nil),
+//		m.On("Search", v7.MatchAny()).Return(lastPage, nil),
+//	)
+func InSequence(calls ...*mock.Call) {
+	for i, call := range calls {
+		if i < len(calls)-1 {
+			call.Once()
+		}
+	}
+}
+
+// MockCalls returns the argument of every call of the @method the @m mock
+// received, in order, such as the SearchConfig of each Search call. Scroll
+// calls return their SearchConfig.
+func MockCalls[T any](m *MockClient, method string) []T {
+	var configs []T
+	for _, call := range m.Calls {
+		if call.Method != method || len(call.Arguments) == 0 {
+			continue
+		}
+		if config, ok := call.Arguments[0].(T); ok {
+			configs = append(configs, config)
+		}
+	}
+	return configs
+}
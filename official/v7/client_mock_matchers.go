@@ -0,0 +1,166 @@
+package v7
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// MatchAny returns a MockClient matcher that matches any argument.
+func MatchAny() interface{} {
+	return mock.Anything
+}
+
+// MatchConfig returns a MockClient matcher that matches the configs of type
+// T, such as SearchConfig, for which @predicate returns true.
+func MatchConfig[T any](predicate func(T) bool) interface{} {
+	return mock.MatchedBy(predicate)
+}
+
+// MatchIndexes returns a MockClient matcher that matches the configs whose
+// indexes are the @indexes, in any order. Configs with a single Index, such
+// as GetConfig, match a single index, while an MGetConfig matches its Index
+// and the Index of each of its documents. A MultiSearch matches when any of
+// its searches matches.
+func MatchIndexes(indexes ...string) interface{} {
+	expected := slices.Clone(indexes)
+	slices.Sort(expected)
+
+	return mock.MatchedBy(func(config interface{}) bool {
+		return matchAnySearch(config, func(config interface{}) bool {
+			actual, ok := configIndexes(config)
+			if !ok {
+				return false
+			}
+			actual = slices.Clone(actual)
+			slices.Sort(actual)
+			return slices.Equal(actual, expected)
+		})
+	})
+}
+
+// MatchFilterContains returns a MockClient matcher that matches the configs
+// whose Filter builds a query that contains the @query, such as a terms
+// query on a single field. The comparison is made on the JSON of the
+// queries, so it does not depend on the order of the keys. A MultiSearch
+// matches when any of its searches matches.
+func MatchFilterContains(query querybuilders.Query) interface{} {
+	expected, err := queryValue(query)
+
+	return mock.MatchedBy(func(config interface{}) bool {
+		if err != nil {
+			return false
+		}
+		return matchAnySearch(config, func(config interface{}) bool {
+			filter, ok := configFilter(config)
+			if !ok {
+				return false
+			}
+			actual, err := buildElasticBoolQuery(filter)
+			if err != nil {
+				return false
+			}
+			value, err := queryValue(actual)
+			return err == nil && containsValue(value, expected)
+		})
+	})
+}
+
+func matchAnySearch(config interface{}, match func(interface{}) bool) bool {
+	configs, ok := config.([]SearchConfig)
+	if !ok {
+		return match(config)
+	}
+	for _, c := range configs {
+		if match(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// nolint: cyclop
+func configIndexes(config interface{}) ([]string, bool) {
+	switch c := config.(type) {
+	case SearchConfig:
+		return c.Indexes, true
+	case CountConfig:
+		return c.Indexes, true
+	case OpenPointInTimeConfig:
+		return c.Indexes, true
+	case DeleteByQueryConfig:
+		return c.Indexes, true
+	case UpdateByQueryConfig:
+		return c.Indexes, true
+	case GetConfig:
+		return []string{c.Index}, true
+	case MGetConfig:
+		return getMGetIndexes(c), true
+	case IndexConfig:
+		return []string{c.Index}, true
+	case UpdateConfig:
+		return []string{c.Index}, true
+	case DeleteConfig:
+		return []string{c.Index}, true
+	case BulkConfig:
+		return []string{c.Index}, true
+	}
+	return nil, false
+}
+
+func configFilter(config interface{}) (Filter, bool) {
+	switch c := config.(type) {
+	case SearchConfig:
+		return c.Filter, true
+	case CountConfig:
+		return c.Filter, true
+	case DeleteByQueryConfig:
+		return c.Filter, true
+	case UpdateByQueryConfig:
+		return c.Filter, true
+	}
+	return Filter{}, false
+}
+
+// queryValue returns the @query as decoded JSON, so queries built in
+// different ways can be compared.
+func queryValue(query querybuilders.Query) (interface{}, error) {
+	source, err := query.Source()
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(b, &value)
+	return value, err
+}
+
+// containsValue reports whether @expected is @value or any value nested in
+// it.
+func containsValue(value, expected interface{}) bool {
+	if reflect.DeepEqual(value, expected) {
+		return true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			if containsValue(child, expected) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if containsValue(child, expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package v7
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+func Test_MockClient_Matchers(t *testing.T) {
+	t.Parallel()
+
+	type filterMust struct {
+		Names []string `es:"Name"`
+		Ages  []uint64 `es:"Age"`
+	}
+
+	config := SearchConfig{
+		Indexes: []string{"index1", "index2"},
+		Size:    10,
+		Filter: Filter{
+			Must: filterMust{
+				Names: []string{"John"},
+				Ages:  []uint64{30},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		matcher  interface{}
+		expected bool
+	}{
+		{
+			name:     "any",
+			matcher:  MatchAny(),
+			expected: true,
+		},
+		{
+			name:     "indexes in any order",
+			matcher:  MatchIndexes("index2", "index1"),
+			expected: true,
+		},
+		{
+			name:     "other indexes",
+			matcher:  MatchIndexes("index1"),
+			expected: false,
+		},
+		{
+			name:     "filter contains",
+			matcher:  MatchFilterContains(querybuilders.NewTermsQuery("Age", uint64(30))),
+			expected: true,
+		},
+		{
+			name:     "filter does not contain",
+			matcher:  MatchFilterContains(querybuilders.NewTermsQuery("Name", "Mary")),
+			expected: false,
+		},
+		{
+			name:     "predicate",
+			matcher:  MatchConfig(func(c SearchConfig) bool { return c.Size == 10 }),
+			expected: true,
+		},
+		{
+			name:     "predicate of another config",
+			matcher:  MatchConfig(func(c CountConfig) bool { return true }),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := &MockClient{}
+			m.On("Search", test.matcher).Return(SearchResponse{Total: 1}, nil)
+
+			if !test.expected {
+				assert.Panics(t, func() {
+					_, _ = m.Search(context.Background(), config)
+				})
+				return
+			}
+			response, err := m.Search(context.Background(), config)
+			require.NoError(t, err)
+			assert.Equal(t, 1, response.Total)
+		})
+	}
+}
+
+func Test_MockClient_InSequence(t *testing.T) {
+	t.Parallel()
+
+	m := NewMockClient(t)
+	InSequence(
+		m.On("Search", MatchIndexes("index")).Return(SearchResponse{IDs: []string{"1"}}, nil),
+		m.On("Search", MatchIndexes("index")).Return(SearchResponse{IDs: []string{"2"}}, nil),
+		m.On("Search", MatchIndexes("index")).Return(SearchResponse{}, nil),
+	)
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		response, err := m.Search(context.Background(), SearchConfig{Indexes: []string{"index"}, Size: i})
+		require.NoError(t, err)
+		ids = append(ids, response.IDs...)
+	}
+
+	assert.Equal(t, []string{"1", "2"}, ids)
+	m.AssertNumberOfCalls(t, "Search", 4)
+
+	calls := MockCalls[SearchConfig](m, "Search")
+	require.Len(t, calls, 4)
+	assert.Equal(t, 3, calls[3].Size)
+}
+
+func Test_MockClient_Methods(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := NewMockClient(t)
+	m.On("Get", MatchIndexes("index")).Return(GetResponse{Found: true}, nil).Once()
+	m.On("MGet", MatchIndexes("index", "other")).Return(MGetResponse{}, nil).Once()
+	m.On("Index", MatchIndexes("index")).Return(WriteResponse{Result: "created"}, nil).Once()
+	m.On("Update", MatchIndexes("index")).Return(WriteResponse{Result: "updated"}, nil).Once()
+	m.On("Delete", MatchIndexes("index")).Return(WriteResponse{Result: "deleted"}, nil).Once()
+	m.On("Bulk", MatchIndexes("index")).Return(BulkResponse{}, nil).Once()
+	m.On("Count", MatchIndexes("index")).Return(CountResponse{Count: 2}, nil).Once()
+	m.On("OpenPointInTime", MatchIndexes("index")).Return("pit", nil).Once()
+	m.On("ClosePointInTime", "pit").Return(nil).Once()
+	m.On("Scroll", MatchIndexes("index"), time.Minute).Return(nil, ErrEndOfScroll).Once()
+	m.On("MultiSearch", MatchIndexes("index")).Return(MultiSearchResponse{}, nil).Once()
+	m.On("DeleteByQuery", MatchIndexes("index")).Return(ByQueryResponse{}, nil).Once()
+	m.On("UpdateByQuery", MatchIndexes("index")).Return(ByQueryResponse{}, nil).Once()
+	m.On("GetTask", "task").Return(TaskResponse{Completed: true}, nil).Once()
+
+	indexes := []string{"index"}
+
+	get, err := m.Get(ctx, GetConfig{Index: "index", ID: "1"})
+	require.NoError(t, err)
+	assert.True(t, get.Found)
+	_, err = m.MGet(ctx, MGetConfig{
+		Index:     "index",
+		Documents: []MGetDocument{{ID: "1"}, {Index: "other", ID: "2"}},
+	})
+	require.NoError(t, err)
+	_, err = m.Index(ctx, IndexConfig{Index: "index"})
+	require.NoError(t, err)
+	_, err = m.Update(ctx, UpdateConfig{Index: "index"})
+	require.NoError(t, err)
+	_, err = m.Delete(ctx, DeleteConfig{Index: "index"})
+	require.NoError(t, err)
+	_, err = m.Bulk(ctx, BulkConfig{Index: "index"})
+	require.NoError(t, err)
+	count, err := m.Count(ctx, CountConfig{Indexes: indexes})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count.Count)
+	id, err := m.OpenPointInTime(ctx, OpenPointInTimeConfig{Indexes: indexes})
+	require.NoError(t, err)
+	require.NoError(t, m.ClosePointInTime(ctx, id))
+	_, err = m.Scroll(ctx, SearchConfig{Indexes: indexes}, time.Minute)
+	assert.ErrorIs(t, err, ErrEndOfScroll)
+	_, err = m.MultiSearch(ctx, []SearchConfig{{Indexes: []string{"other"}}, {Indexes: indexes}})
+	require.NoError(t, err)
+	_, err = m.DeleteByQuery(ctx, DeleteByQueryConfig{Indexes: indexes})
+	require.NoError(t, err)
+	_, err = m.UpdateByQuery(ctx, UpdateByQueryConfig{Indexes: indexes})
+	require.NoError(t, err)
+	task, err := m.GetTask(ctx, "task")
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+}
+
+func Test_MockScrollCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cursor := NewMockScrollCursor(t)
+	InSequence(
+		cursor.On("Next").Return(SearchResponse{IDs: []string{"1"}}, nil),
+		cursor.On("Next").Return(SearchResponse{}, ErrEndOfScroll),
+	)
+	cursor.On("Close").Return(nil).Once()
+
+	m := NewMockClient(t)
+	m.On("Scroll", MatchIndexes("index"), time.Minute).Return(cursor, nil).Once()
+
+	scroll, err := m.Scroll(ctx, SearchConfig{Indexes: []string{"index"}, Size: 1}, time.Minute)
+	require.NoError(t, err)
+
+	var ids []string
+	for {
+		response, err := scroll.Next(ctx)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrEndOfScroll)
+			break
+		}
+		ids = append(ids, response.IDs...)
+	}
+	require.NoError(t, scroll.Close(ctx))
+	assert.Equal(t, []string{"1"}, ids)
+}
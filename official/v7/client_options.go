@@ -0,0 +1,338 @@
+package v7
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	es "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/estransport"
+)
+
+// Option configures the Client created by NewClientWithOptions.
+type Option func(*clientOptions) error
+
+type clientOptions struct {
+	config         es.Config
+	transport      http.RoundTripper
+	tlsConfig      *tls.Config
+	requestTimeout time.Duration
+	retryPolicy    RetryPolicy
+	credentials    CredentialsProvider
+	metrics        Metrics
+	tracer         Tracer
+	fullQuery      bool
+	redactSpans    bool
+	logConfig      LogConfig
+	slowLog        SlowLogConfig
+	paginatorCodec *PaginatorCodec
+}
+
+// NewClientWithOptions returns a new Client using the @urls, configured by
+// the @options.
+//
+// By default, the Client verifies the server certificate against the
+// system's certificate pool and retries Search, Count, Get, MGet and
+// MultiSearch according to the DefaultRetryPolicy. Requests are not retried at the transport level unless
+// WithMaxRetries is given, so they are not retried by both layers.
+func NewClientWithOptions(urls []string, options ...Option) (Client, error) {
+	const op = errors.Op("v7.NewClientWithOptions")
+
+	o := clientOptions{
+		config: es.Config{
+			Addresses:    urls,
+			DisableRetry: true,
+		},
+		retryPolicy: DefaultRetryPolicy(),
+	}
+	for _, option := range options {
+		err := option(&o)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+
+	transport, err := o.buildTransport()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	o.config.Transport = transport
+
+	client, err := es.NewClient(o.config)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	var c Client = &esClient{
+		client:         client,
+		retryPolicy:    o.retryPolicy,
+		paginatorCodec: o.paginatorCodec,
+	}
+
+	i := instrumentation{
+		metrics:          o.metrics,
+		tracer:           o.tracer,
+		captureFullQuery: o.fullQuery,
+		redactStatement:  o.redactSpans,
+		logConfig:        o.logConfig,
+		slowLog:          o.slowLog,
+	}
+	if i.enabled() {
+		c = &instrumentedClient{
+			next:            c,
+			instrumentation: i,
+		}
+	}
+
+	return c, nil
+}
+
+// MustNewClientWithOptions works like NewClientWithOptions, but it panics
+// instead of returning an error.
+func MustNewClientWithOptions(urls []string, options ...Option) Client {
+	client, err := NewClientWithOptions(urls, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return client
+}
+
+func (o *clientOptions) buildTransport() (http.RoundTripper, error) {
+	transport := o.transport
+	if transport == nil {
+		transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: o.tlsConfig,
+		}
+	} else if o.tlsConfig != nil {
+		return nil, errors.New("TLS options cannot be used with a custom transport")
+	}
+
+	if o.credentials != nil {
+		transport = &credentialsTransport{
+			next:     transport,
+			provider: o.credentials,
+		}
+	}
+
+	if o.metrics != nil || o.tracer != nil {
+		transport = &callInfoTransport{next: transport}
+	}
+
+	if o.requestTimeout > 0 {
+		transport = &timeoutTransport{
+			next:    transport,
+			timeout: o.requestTimeout,
+		}
+	}
+
+	return transport, nil
+}
+
+func (o *clientOptions) getTLSConfig() *tls.Config {
+	if o.tlsConfig == nil {
+		o.tlsConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	return o.tlsConfig
+}
+
+// WithTransport makes the Client send its requests through the
+// @transport. It cannot be used with the TLS options, which must be set in
+// the @transport itself.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) error {
+		o.transport = transport
+		return nil
+	}
+}
+
+// WithBasicAuth authenticates the requests with the @username and
+// @password.
+func WithBasicAuth(username, password string) Option {
+	return func(o *clientOptions) error {
+		o.config.Username = username
+		o.config.Password = password
+		return nil
+	}
+}
+
+// WithAPIKey authenticates the requests with the base64 encoded @apiKey.
+// It takes precedence over the other authentication options.
+func WithAPIKey(apiKey string) Option {
+	return func(o *clientOptions) error {
+		o.config.APIKey = apiKey
+		return nil
+	}
+}
+
+// WithBearerToken authenticates the requests with the bearer @token, such
+// as a service account token. It takes precedence over the basic auth.
+func WithBearerToken(token string) Option {
+	return func(o *clientOptions) error {
+		o.config.ServiceToken = token
+		return nil
+	}
+}
+
+// WithCloudID connects the Client to the Elastic Cloud deployment
+// identified by the @cloudID. The urls given to NewClientWithOptions must be
+// empty.
+func WithCloudID(cloudID string) Option {
+	return func(o *clientOptions) error {
+		o.config.CloudID = cloudID
+		return nil
+	}
+}
+
+// WithCredentialsProvider authenticates each request with the Credentials
+// returned by the @provider, so they can be rotated without creating a new
+// Client. It takes precedence over the other authentication options.
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(o *clientOptions) error {
+		o.credentials = provider
+		return nil
+	}
+}
+
+// WithCACert verifies the server certificate against the PEM encoded
+// certificate authorities in @certPem instead of the system's pool.
+func WithCACert(certPem []byte) Option {
+	return func(o *clientOptions) error {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(certPem) {
+			return errors.New("failed to append CA certificate")
+		}
+		o.getTLSConfig().RootCAs = pool
+		return nil
+	}
+}
+
+// WithCAPool verifies the server certificate against the @pool instead of
+// the system's pool.
+func WithCAPool(pool *x509.CertPool) Option {
+	return func(o *clientOptions) error {
+		o.getTLSConfig().RootCAs = pool
+		return nil
+	}
+}
+
+// WithClientCertificate authenticates the client with the PEM encoded
+// @certPem and @keyPem, for mutual TLS.
+func WithClientCertificate(certPem, keyPem []byte) Option {
+	return func(o *clientOptions) error {
+		certificate, err := tls.X509KeyPair(certPem, keyPem)
+		if err != nil {
+			return err
+		}
+		tlsConfig := o.getTLSConfig()
+		tlsConfig.Certificates = append(tlsConfig.Certificates, certificate)
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server
+// certificate. It must only be used on development environments.
+func WithInsecureSkipVerify() Option {
+	return func(o *clientOptions) error {
+		o.getTLSConfig().InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// WithCompression compresses the request bodies with gzip. Compressed
+// responses are always accepted.
+func WithCompression() Option {
+	return func(o *clientOptions) error {
+		o.config.CompressRequestBody = true
+		return nil
+	}
+}
+
+// WithMaxRetries enables the retries at the transport level, which retry a
+// request up to @maxRetries times when elasticsearch replies with status
+// 502, 503 or 504, waiting as the DefaultRetryPolicy does. They apply to
+// every request and run below the RetryPolicy, so they are usually combined
+// with a zero RetryPolicy. A zero @maxRetries disables these retries.
+func WithMaxRetries(maxRetries int) Option {
+	return func(o *clientOptions) error {
+		o.config.MaxRetries = maxRetries
+		o.config.DisableRetry = maxRetries <= 0
+		o.config.RetryBackoff = DefaultRetryPolicy().backoff
+		return nil
+	}
+}
+
+// WithRetryPolicy makes the Client retry failed operations according to the
+// @policy. A zero RetryPolicy disables these retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) error {
+		o.retryPolicy = policy
+		return nil
+	}
+}
+
+// WithRequestTimeout limits how long each request to elasticsearch may
+// take, including reading its response body.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) error {
+		o.requestTimeout = timeout
+		return nil
+	}
+}
+
+// WithHeader adds the header @key with the @value to every request.
+func WithHeader(key, value string) Option {
+	return func(o *clientOptions) error {
+		if o.config.Header == nil {
+			o.config.Header = make(http.Header)
+		}
+		o.config.Header.Add(key, value)
+		return nil
+	}
+}
+
+// WithLogger makes the underlying transport log its requests and responses
+// to the @logger, such as an estransport.TextLogger.
+func WithLogger(logger estransport.Logger) Option {
+	return func(o *clientOptions) error {
+		o.config.Logger = logger
+		return nil
+	}
+}
+
+// timeoutTransport cancels each request after the timeout. The request is
+// only released when its response body is closed.
+type timeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *timeoutTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(r.Context(), t.timeout)
+
+	response, err := t.next.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,151 @@
+package v7
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func Test_NewClientWithOptions(t *testing.T) {
+	t.Parallel()
+
+	var request *http.Request
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		request = r
+		response := &http.Response{
+			StatusCode: 200,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"count":1}`)),
+		}
+		response.Header.Add("X-Elastic-Product", "Elasticsearch")
+		return response, nil
+	})
+
+	client, err := NewClientWithOptions(
+		[]string{"http://localhost:9200"},
+		WithTransport(transport),
+		WithAPIKey("api-key"),
+		WithHeader("X-Opaque-Id", "request-1"),
+		WithRequestTimeout(time.Second),
+		WithRetryPolicy(RetryPolicy{}),
+	)
+	require.NoError(t, err)
+
+	response, err := client.Count(context.Background(), CountConfig{Indexes: []string{"index1"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Count)
+
+	assert.Equal(t, "APIKey api-key", request.Header.Get("Authorization"))
+	assert.Equal(t, "request-1", request.Header.Get("X-Opaque-Id"))
+	_, hasDeadline := request.Context().Deadline()
+	assert.True(t, hasDeadline)
+	assert.Equal(t, RetryPolicy{}, client.(*esClient).retryPolicy)
+}
+
+func Test_NewClientWithOptions_Retries(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		options          []Option
+		expectedRequests int32
+	}{
+		{
+			name:             "retry policy only",
+			options:          []Option{WithRetryPolicy(RetryPolicy{MaxAttempts: 2, RetryOnStatus: []int{503}})},
+			expectedRequests: 2,
+		},
+		{
+			name:             "transport retries only",
+			options:          []Option{WithRetryPolicy(RetryPolicy{}), WithMaxRetries(1)},
+			expectedRequests: 2,
+		},
+		{
+			name:             "no retries",
+			options:          []Option{WithRetryPolicy(RetryPolicy{})},
+			expectedRequests: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				response := &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     make(http.Header),
+					Body:       io.NopCloser(strings.NewReader(`{"version":{"number":"7.17.10"}}`)),
+				}
+				if r.URL.Path != "/" {
+					requests.Add(1)
+					response.StatusCode = http.StatusServiceUnavailable
+					response.Body = io.NopCloser(strings.NewReader(`{"error":{"type":"unavailable"},"status":503}`))
+				}
+				response.Header.Add("X-Elastic-Product", "Elasticsearch")
+				return response, nil
+			})
+
+			options := append([]Option{WithTransport(transport)}, test.options...)
+			client, err := NewClientWithOptions([]string{"http://localhost:9200"}, options...)
+			require.NoError(t, err)
+
+			_, err = client.Search(context.Background(), SearchConfig{Indexes: []string{"index1"}, Size: 1})
+			assert.Error(t, err)
+			assert.Equal(t, test.expectedRequests, requests.Load())
+		})
+	}
+}
+
+func Test_NewClientWithOptions_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedError string
+	}{
+		{
+			name:          "invalid CA certificate",
+			options:       []Option{WithCACert([]byte("invalid"))},
+			expectedError: "v7.NewClientWithOptions: failed to append CA certificate",
+		},
+		{
+			name: "TLS options with custom transport",
+			options: []Option{
+				WithTransport(http.DefaultTransport),
+				WithInsecureSkipVerify(),
+			},
+			expectedError: "v7.NewClientWithOptions: TLS options cannot be used with a custom transport",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client, err := NewClientWithOptions([]string{"http://localhost:9200"}, test.options...)
+			assert.EqualError(t, err, test.expectedError)
+			assert.Nil(t, client)
+		})
+	}
+}
+
+func Test_NewClientWithAuth_InvalidCACert(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClientWithAuth([]string{"http://localhost:9200"}, []byte("invalid"), "user", "password")
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
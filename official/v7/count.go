@@ -0,0 +1,130 @@
+package v7
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// CountConfig hold all information to Count method.
+type CountConfig struct {
+	Indexes           []string
+	Filter            Filter
+	IgnoreUnavailable bool
+	AllowNoIndices    bool
+}
+
+// CountResponse represents the response for Count method.
+type CountResponse struct {
+	Count  int
+	Shards ShardsInfo
+}
+
+type envelopeCountResponse struct {
+	Count  int         `json:"count"`
+	Shards *ShardsInfo `json:"_shards,omitempty"`
+}
+
+// Count returns how many documents match the @config's Filter, without
+// fetching or sorting any hit.
+func (c *esClient) Count(ctx context.Context, config CountConfig) (CountResponse, error) {
+	const op = errors.Op("v7.Client.Count")
+
+	enrichLogWithIndexes(ctx, config.Indexes)
+
+	var countResponse CountResponse
+	err := c.retryPolicy.do(ctx, func() error {
+		var err error
+		countResponse, err = c.count(ctx, config)
+		return err
+	})
+	if err != nil {
+		return CountResponse{}, errors.E(op, err)
+	}
+
+	return countResponse, nil
+}
+
+func (c *esClient) count(ctx context.Context, config CountConfig) (CountResponse, error) {
+	response, err := c.doCount(ctx, config)
+	if err != nil {
+		return CountResponse{}, err
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseCountResponse(ctx, response)
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeUnexpectedResponse)
+		}
+		return CountResponse{}, err
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doCount(ctx context.Context, config CountConfig) (*esapi.Response, error) {
+	const op = errors.Op("doCount")
+
+	query, err := buildElasticBoolQuery(config.Filter)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	queryString := `{"query":` + marshalQuery(query) + `}`
+	enrichLogWithQuery(ctx, queryString, func() string {
+		return `{"query":` + marshalQuery(querybuilders.Redact(query)) + `}`
+	})
+
+	response, err := c.client.Count(
+		c.client.Count.WithContext(ctx),
+		c.client.Count.WithIndex(config.Indexes...),
+		c.client.Count.WithBody(strings.NewReader(queryString)),
+		c.client.Count.WithIgnoreUnavailable(config.IgnoreUnavailable),
+		c.client.Count.WithAllowNoIndices(config.AllowNoIndices),
+	)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+func parseCountResponse(ctx context.Context, response *esapi.Response) (CountResponse, error) {
+	const op = errors.Op("parseCountResponse")
+
+	var r envelopeCountResponse
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return CountResponse{}, errors.E(op, err)
+	}
+
+	enrichLogWithShards(ctx, getTotalShards(r.Shards))
+	recordShards(ctx, r.Shards)
+
+	err = checkShards(r.Shards)
+	if err != nil {
+		return CountResponse{}, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	countResponse := CountResponse{Count: r.Count}
+	if r.Shards != nil {
+		countResponse.Shards = *r.Shards
+	}
+
+	return countResponse, nil
+}
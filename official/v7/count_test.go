@@ -0,0 +1,117 @@
+package v7
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Count(t *testing.T) {
+	t.Parallel()
+	type countFilterMust struct {
+		Names []string `es:"Name"`
+	}
+
+	tests := []struct {
+		name              string
+		config            CountConfig
+		transport         *mockTransport
+		expectedResponse  CountResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: CountConfig{
+				Indexes: []string{"index1", "index2"},
+				Filter: Filter{
+					Must: countFilterMust{
+						Names: []string{"John", "Mary"},
+					},
+				},
+				IgnoreUnavailable: true,
+				AllowNoIndices:    true,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1,index2/_count?allow_no_indices=true&ignore_unavailable=true",
+					`{"query":{"terms":{"Name":["John","Mary"]}}}`,
+				).Once().Return(
+					`{"count":42,"_shards":{"total":2,"successful":2,"skipped":0,"failed":0}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: CountResponse{
+				Count: 42,
+				Shards: ShardsInfo{
+					Total:      2,
+					Successful: 2,
+				},
+			},
+		},
+		{
+			name: "not all shards replied",
+			config: CountConfig{
+				Indexes: []string{"index1"},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_count?allow_no_indices=false&ignore_unavailable=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"count":10,"_shards":{"total":2,"successful":1,"skipped":0,"failed":1}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.Count: parseCountResponse: not all shards replied",
+			expectedErrorCode: ErrCodeBadGateway,
+		},
+		{
+			name: "index not found",
+			config: CountConfig{
+				Indexes: []string{"index1"},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_count?allow_no_indices=false&ignore_unavailable=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index1]"}],"type":"index_not_found_exception","reason":"no such index [index1]"},"status":404}`,
+					404,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.Count: doCount: [404 Not Found] index_not_found_exception: no such index [index1]: no such index [index1]",
+			expectedErrorCode: ErrCodeNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := client.Count(context.Background(), test.config)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
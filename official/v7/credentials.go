@@ -0,0 +1,78 @@
+package v7
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// Credentials represents how a request is authenticated. When more than one
+// is set, APIKey takes precedence over BearerToken, which takes precedence
+// over Username and Password.
+type Credentials struct {
+	Username string
+	Password string
+	// APIKey is the base64 encoded API key, as returned in the encoded
+	// field by elasticsearch. EncodeAPIKey builds it from the id and the
+	// api_key fields.
+	APIKey string
+	// BearerToken is a service account token or an OAuth2 access token.
+	BearerToken string
+}
+
+// CredentialsProvider returns the Credentials used to authenticate a
+// request. It is called on every request and must be safe for concurrent
+// use.
+type CredentialsProvider func(ctx context.Context) (Credentials, error)
+
+// StaticCredentials returns a CredentialsProvider that always returns the
+// @credentials.
+func StaticCredentials(credentials Credentials) CredentialsProvider {
+	return func(context.Context) (Credentials, error) {
+		return credentials, nil
+	}
+}
+
+// EncodeAPIKey returns the base64 encoded API key built from its @id and
+// @apiKey.
+func EncodeAPIKey(id, apiKey string) string {
+	return base64.StdEncoding.EncodeToString([]byte(id + ":" + apiKey))
+}
+
+func (c Credentials) setAuthorization(header http.Header) {
+	switch {
+	case c.APIKey != "":
+		header.Set("Authorization", "APIKey "+c.APIKey)
+	case c.BearerToken != "":
+		header.Set("Authorization", "Bearer "+c.BearerToken)
+	case c.Username != "" || c.Password != "":
+		request := http.Request{Header: header}
+		request.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// credentialsTransport authenticates each request with the credentials
+// returned by the provider.
+type credentialsTransport struct {
+	next     http.RoundTripper
+	provider CredentialsProvider
+}
+
+func (t *credentialsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	const op = errors.Op("credentialsProvider")
+
+	credentials, err := t.provider(r.Context())
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	r = r.Clone(r.Context())
+	if r.Header == nil {
+		r.Header = make(http.Header)
+	}
+	credentials.setAuthorization(r.Header)
+
+	return t.next.RoundTrip(r)
+}
@@ -0,0 +1,83 @@
+package v7
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithCredentialsProvider(t *testing.T) {
+	t.Parallel()
+
+	var authorizations []string
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		// The first request is the product check of the elasticsearch client.
+		if r.URL.Path != "/" {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+		}
+		response := &http.Response{
+			StatusCode: 200,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"count":1}`)),
+		}
+		response.Header.Add("X-Elastic-Product", "Elasticsearch")
+		return response, nil
+	})
+
+	var current Credentials
+	var currentErr error
+	provider := func(context.Context) (Credentials, error) {
+		return current, currentErr
+	}
+
+	client, err := NewClientWithOptions(
+		[]string{"http://localhost:9200"},
+		WithTransport(transport),
+		WithBasicAuth("static", "static"),
+		WithCredentialsProvider(provider),
+		WithMaxRetries(0),
+		WithRetryPolicy(RetryPolicy{}),
+	)
+	require.NoError(t, err)
+
+	rotations := []Credentials{
+		{APIKey: EncodeAPIKey("id", "key-1")},
+		{BearerToken: "token-2"},
+		{Username: "user", Password: "password-3"},
+	}
+	for _, credentials := range rotations {
+		current = credentials
+		_, err = client.Count(context.Background(), CountConfig{})
+		require.NoError(t, err)
+	}
+
+	currentErr = errors.New("no credentials")
+	_, err = client.Count(context.Background(), CountConfig{})
+	assert.ErrorContains(t, err, "credentialsProvider: no credentials")
+
+	assert.Equal(t, []string{
+		"APIKey aWQ6a2V5LTE=",
+		"Bearer token-2",
+		"Basic dXNlcjpwYXNzd29yZC0z",
+	}, authorizations)
+}
+
+func Test_WithCloudID(t *testing.T) {
+	t.Parallel()
+
+	cloudID := "name:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io$cluster-id$kibana-id"))
+
+	client, err := NewClientWithOptions(nil, WithCloudID(cloudID))
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+
+	_, err = NewClientWithOptions([]string{"http://localhost:9200"}, WithCloudID(cloudID))
+	assert.ErrorContains(t, err, "both Addresses and CloudID are set")
+}
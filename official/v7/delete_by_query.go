@@ -0,0 +1,97 @@
+package v7
+
+import (
+	"context"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// DeleteByQueryConfig hold all information to DeleteByQuery method.
+//
+// A zero Filter matches every document, so it is rejected unless MatchAll
+// is true. Slices splits the operation in parallel slices, and SlicesAuto
+// lets elasticsearch choose them. RequestsPerSecond throttles the operation,
+// and nil or -1 leave it unthrottled. If Async is true, the method returns
+// right away with the TaskID of the operation.
+type DeleteByQueryConfig struct {
+	Indexes           []string
+	Filter            Filter
+	Conflicts         Conflicts
+	Slices            int
+	RequestsPerSecond *float64
+	MatchAll          bool
+	Refresh           bool
+	Async             bool
+	IgnoreUnavailable bool
+	AllowNoIndices    bool
+}
+
+// DeleteByQuery removes all documents that match the @config's Filter. If
+// elasticsearch stops the operation, such as on a version conflict with
+// ConflictsAbort, the error holds a ByQueryError.
+func (c *esClient) DeleteByQuery(ctx context.Context, config DeleteByQueryConfig) (ByQueryResponse, error) {
+	const op = errors.Op("v7.Client.DeleteByQuery")
+
+	enrichLogWithIndexes(ctx, config.Indexes)
+
+	response, err := c.doDeleteByQuery(ctx, config)
+	if err != nil {
+		return ByQueryResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseByQueryResponse(response)
+	if err != nil {
+		return ByQueryResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doDeleteByQuery(ctx context.Context, config DeleteByQueryConfig) (*esapi.Response, error) {
+	const op = errors.Op("doDeleteByQuery")
+
+	if len(config.Indexes) == 0 {
+		return nil, errors.E(op, requiredFieldError("Indexes"), ErrCodeBadRequest)
+	}
+
+	body, err := getByQueryBody(config.Filter, config.MatchAll, nil)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.DeleteByQueryRequest){
+		c.client.DeleteByQuery.WithContext(ctx),
+		c.client.DeleteByQuery.WithIgnoreUnavailable(config.IgnoreUnavailable),
+		c.client.DeleteByQuery.WithAllowNoIndices(config.AllowNoIndices),
+	}
+	if config.Conflicts != "" {
+		options = append(options, c.client.DeleteByQuery.WithConflicts(string(config.Conflicts)))
+	}
+	if config.Slices != 0 {
+		options = append(options, c.client.DeleteByQuery.WithSlices(slicesParam(config.Slices)))
+	}
+	if config.Refresh {
+		options = append(options, c.client.DeleteByQuery.WithRefresh(true))
+	}
+	if config.Async {
+		options = append(options, c.client.DeleteByQuery.WithWaitForCompletion(false))
+	}
+
+	response, err := c.byQueryAPI(config.RequestsPerSecond).DeleteByQuery(config.Indexes, body, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkByQueryErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
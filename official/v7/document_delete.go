@@ -0,0 +1,74 @@
+package v7
+
+import (
+	"context"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// DeleteConfig hold all information to Delete method.
+type DeleteConfig struct {
+	Index         string
+	ID            string
+	IfSeqNo       *int
+	IfPrimaryTerm *int
+	Routing       string
+	Refresh       Refresh
+}
+
+// Delete removes the document identified by @config's Index and ID. If the
+// document does not exist, ErrDocumentNotFound is returned with the
+// ErrCodeNotFound code.
+func (c *esClient) Delete(ctx context.Context, config DeleteConfig) (WriteResponse, error) {
+	const op = errors.Op("v7.Client.Delete")
+
+	enrichLogWithIndexes(ctx, []string{config.Index})
+
+	response, err := c.doDelete(ctx, config)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseWriteResponse(response)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doDelete(ctx context.Context, config DeleteConfig) (*esapi.Response, error) {
+	const op = errors.Op("doDelete")
+
+	if config.Index == "" {
+		return nil, errors.E(op, requiredFieldError("Index"), ErrCodeBadRequest)
+	}
+	if config.ID == "" {
+		return nil, errors.E(op, requiredFieldError("ID"), ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.DeleteRequest){
+		c.client.Delete.WithContext(ctx),
+	}
+	if config.IfSeqNo != nil {
+		options = append(options, c.client.Delete.WithIfSeqNo(*config.IfSeqNo))
+	}
+	if config.IfPrimaryTerm != nil {
+		options = append(options, c.client.Delete.WithIfPrimaryTerm(*config.IfPrimaryTerm))
+	}
+	if config.Routing != "" {
+		options = append(options, c.client.Delete.WithRouting(config.Routing))
+	}
+	if config.Refresh != "" {
+		options = append(options, c.client.Delete.WithRefresh(string(config.Refresh)))
+	}
+
+	response, err := c.client.Delete(config.Index, config.ID, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	return checkDocumentResponse(op, response)
+}
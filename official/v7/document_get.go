@@ -0,0 +1,90 @@
+package v7
+
+import (
+	"context"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// GetConfig hold all information to Get method.
+type GetConfig struct {
+	Index        string
+	ID           string
+	Routing      string
+	Source       SourceFilter
+	StoredFields []string
+}
+
+// Get returns the document identified by @config's Index and ID. If the
+// document does not exist, ErrDocumentNotFound is returned with the
+// ErrCodeNotFound code.
+func (c *esClient) Get(ctx context.Context, config GetConfig) (GetResponse, error) {
+	const op = errors.Op("v7.Client.Get")
+
+	enrichLogWithIndexes(ctx, []string{config.Index})
+
+	var getResponse GetResponse
+	err := c.retryPolicy.do(ctx, func() error {
+		var err error
+		getResponse, err = c.get(ctx, config)
+		return err
+	})
+	if err != nil {
+		return GetResponse{}, errors.E(op, err)
+	}
+
+	return getResponse, nil
+}
+
+func (c *esClient) get(ctx context.Context, config GetConfig) (GetResponse, error) {
+	response, err := c.doGet(ctx, config)
+	if err != nil {
+		return GetResponse{}, err
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseGetResponse(response)
+	if err != nil {
+		return GetResponse{}, errors.E(err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doGet(ctx context.Context, config GetConfig) (*esapi.Response, error) {
+	const op = errors.Op("doGet")
+
+	if config.Index == "" {
+		return nil, errors.E(op, requiredFieldError("Index"), ErrCodeBadRequest)
+	}
+	if config.ID == "" {
+		return nil, errors.E(op, requiredFieldError("ID"), ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.GetRequest){
+		c.client.Get.WithContext(ctx),
+	}
+	if config.Routing != "" {
+		options = append(options, c.client.Get.WithRouting(config.Routing))
+	}
+	if config.Source.Disabled {
+		options = append(options, c.client.Get.WithSource("false"))
+	}
+	if len(config.Source.Includes) > 0 {
+		options = append(options, c.client.Get.WithSourceIncludes(config.Source.Includes...))
+	}
+	if len(config.Source.Excludes) > 0 {
+		options = append(options, c.client.Get.WithSourceExcludes(config.Source.Excludes...))
+	}
+	if len(config.StoredFields) > 0 {
+		options = append(options, c.client.Get.WithStoredFields(config.StoredFields...))
+	}
+
+	response, err := c.client.Get(config.Index, config.ID, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	return checkDocumentResponse(op, response)
+}
@@ -0,0 +1,98 @@
+package v7
+
+import (
+	"context"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// IndexConfig hold all information to Index method.
+//
+// If ID is empty, elasticsearch generates one. Version and VersionType are
+// used for external versioning, while IfSeqNo and IfPrimaryTerm are used for
+// optimistic concurrency control. A version conflict is returned with the
+// ErrCodeConflict code.
+type IndexConfig struct {
+	Index         string
+	ID            string
+	Document      interface{}
+	OpType        OpType
+	Version       *int
+	VersionType   string
+	IfSeqNo       *int
+	IfPrimaryTerm *int
+	Routing       string
+	Refresh       Refresh
+}
+
+// Index stores the @config's Document in the @config's Index.
+func (c *esClient) Index(ctx context.Context, config IndexConfig) (WriteResponse, error) {
+	const op = errors.Op("v7.Client.Index")
+
+	enrichLogWithIndexes(ctx, []string{config.Index})
+
+	response, err := c.doIndex(ctx, config)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseWriteResponse(response)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doIndex(ctx context.Context, config IndexConfig) (*esapi.Response, error) {
+	const op = errors.Op("doIndex")
+
+	if config.Index == "" {
+		return nil, errors.E(op, requiredFieldError("Index"), ErrCodeBadRequest)
+	}
+	if config.Document == nil {
+		return nil, errors.E(op, requiredFieldError("Document"), ErrCodeBadRequest)
+	}
+
+	body, err := encodeBody(config.Document)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.IndexRequest){
+		c.client.Index.WithContext(ctx),
+	}
+	if config.ID != "" {
+		options = append(options, c.client.Index.WithDocumentID(config.ID))
+	}
+	if config.OpType != "" {
+		options = append(options, c.client.Index.WithOpType(string(config.OpType)))
+	}
+	if config.Version != nil {
+		options = append(options, c.client.Index.WithVersion(*config.Version))
+	}
+	if config.VersionType != "" {
+		options = append(options, c.client.Index.WithVersionType(config.VersionType))
+	}
+	if config.IfSeqNo != nil {
+		options = append(options, c.client.Index.WithIfSeqNo(*config.IfSeqNo))
+	}
+	if config.IfPrimaryTerm != nil {
+		options = append(options, c.client.Index.WithIfPrimaryTerm(*config.IfPrimaryTerm))
+	}
+	if config.Routing != "" {
+		options = append(options, c.client.Index.WithRouting(config.Routing))
+	}
+	if config.Refresh != "" {
+		options = append(options, c.client.Index.WithRefresh(string(config.Refresh)))
+	}
+
+	response, err := c.client.Index(config.Index, body, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	return checkDocumentResponse(op, response)
+}
@@ -0,0 +1,140 @@
+package v7
+
+import (
+	"context"
+	"io"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// MGetConfig hold all information to MGet method.
+//
+// Index is used for every document that does not set its own index.
+type MGetConfig struct {
+	Index        string
+	Documents    []MGetDocument
+	Source       SourceFilter
+	StoredFields []string
+}
+
+// MGetDocument identifies a document requested by the MGet method.
+type MGetDocument struct {
+	Index   string
+	ID      string
+	Routing string
+}
+
+// MGet returns all the documents requested in @config in a single request.
+// Missing documents are returned with Found set to false, and documents that
+// elasticsearch failed to get are returned with their Err set.
+func (c *esClient) MGet(ctx context.Context, config MGetConfig) (MGetResponse, error) {
+	const op = errors.Op("v7.Client.MGet")
+
+	enrichLogWithIndexes(ctx, getMGetIndexes(config))
+
+	var mgetResponse MGetResponse
+	err := c.retryPolicy.do(ctx, func() error {
+		var err error
+		mgetResponse, err = c.mget(ctx, config)
+		return err
+	})
+	if err != nil {
+		return MGetResponse{}, errors.E(op, err)
+	}
+
+	return mgetResponse, nil
+}
+
+func (c *esClient) mget(ctx context.Context, config MGetConfig) (MGetResponse, error) {
+	response, err := c.doMGet(ctx, config)
+	if err != nil {
+		return MGetResponse{}, err
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseMGetResponse(response)
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeUnexpectedResponse)
+		}
+		return MGetResponse{}, err
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doMGet(ctx context.Context, config MGetConfig) (*esapi.Response, error) {
+	const op = errors.Op("doMGet")
+
+	body, err := getMGetBody(config)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.MgetRequest){
+		c.client.Mget.WithContext(ctx),
+	}
+	if config.Index != "" {
+		options = append(options, c.client.Mget.WithIndex(config.Index))
+	}
+	if config.Source.Disabled {
+		options = append(options, c.client.Mget.WithSource("false"))
+	}
+	if len(config.Source.Includes) > 0 {
+		options = append(options, c.client.Mget.WithSourceIncludes(config.Source.Includes...))
+	}
+	if len(config.Source.Excludes) > 0 {
+		options = append(options, c.client.Mget.WithSourceExcludes(config.Source.Excludes...))
+	}
+	if len(config.StoredFields) > 0 {
+		options = append(options, c.client.Mget.WithStoredFields(config.StoredFields...))
+	}
+
+	response, err := c.client.Mget(body, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	return checkDocumentResponse(op, response)
+}
+
+func getMGetBody(config MGetConfig) (io.Reader, error) {
+	const op = errors.Op("getMGetBody")
+
+	if len(config.Documents) == 0 {
+		return nil, errors.E(op, requiredFieldError("Documents"))
+	}
+
+	docs := make([]map[string]interface{}, 0, len(config.Documents))
+	for _, document := range config.Documents {
+		if document.ID == "" {
+			return nil, errors.E(op, requiredFieldError("ID"))
+		}
+		if document.Index == "" && config.Index == "" {
+			return nil, errors.E(op, requiredFieldError("Index"))
+		}
+
+		doc := map[string]interface{}{
+			"_id": document.ID,
+		}
+		if document.Index != "" {
+			doc["_index"] = document.Index
+		}
+		if document.Routing != "" {
+			doc["routing"] = document.Routing
+		}
+		docs = append(docs, doc)
+	}
+
+	return encodeBody(map[string]interface{}{"docs": docs})
+}
+
+func getMGetIndexes(config MGetConfig) []string {
+	indexes := make([]string, 0, len(config.Documents)+1)
+	indexes = append(indexes, config.Index)
+	for _, document := range config.Documents {
+		indexes = append(indexes, document.Index)
+	}
+	return uniqueIndexes(indexes)
+}
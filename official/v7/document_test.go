@@ -0,0 +1,319 @@
+package v7
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/arquivei/foundationkit/ref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+func Test_Get(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		config            GetConfig
+		transport         *mockTransport
+		expectedResponse  GetResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: GetConfig{
+				Index:  "index1",
+				ID:     "id-1",
+				Source: SourceFilter{Includes: []string{"Name"}},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_doc/id-1?_source_includes=Name",
+					"",
+				).Once().Return(
+					`{"_index":"index1","_type":"_doc","_id":"id-1","_version":3,"_seq_no":10,"_primary_term":1,"found":true,"_source":{"Name":"John"}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: GetResponse{
+				ID:          "id-1",
+				Index:       "index1",
+				Version:     3,
+				SeqNo:       10,
+				PrimaryTerm: 1,
+				Found:       true,
+				Source:      []byte(`{"Name":"John"}`),
+			},
+		},
+		{
+			name: "document not found",
+			config: GetConfig{
+				Index: "index1",
+				ID:    "id-1",
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_doc/id-1",
+					"",
+				).Once().Return(
+					`{"_index":"index1","_type":"_doc","_id":"id-1","found":false}`,
+					404,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.Get: doGet: document not found",
+			expectedErrorCode: ErrCodeNotFound,
+		},
+		{
+			name: "index not found",
+			config: GetConfig{
+				Index: "index1",
+				ID:    "id-1",
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_doc/id-1",
+					"",
+				).Once().Return(
+					`{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index1]"}],"type":"index_not_found_exception","reason":"no such index [index1]"},"status":404}`,
+					404,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.Get: doGet: [404 Not Found] index_not_found_exception: no such index [index1]: no such index [index1]",
+			expectedErrorCode: ErrCodeNotFound,
+		},
+		{
+			name: "missing id",
+			config: GetConfig{
+				Index: "index1",
+			},
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.Get: doGet: [ID] is required",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := client.Get(context.Background(), test.config)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+		})
+	}
+}
+
+func Test_MGet(t *testing.T) {
+	t.Parallel()
+
+	server := new(mockTransport)
+	server.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_mget",
+		`{"docs":[{"_id":"id-1"},{"_id":"id-2","_index":"index2","routing":"r1"},{"_id":"id-3","_index":"index3"}]}`,
+	).Once().Return(
+		`{"docs":[{"_index":"index1","_id":"id-1","_version":1,"_seq_no":0,"_primary_term":1,"found":true,"_source":{"Name":"John"}},{"_index":"index2","_id":"id-2","found":false},{"_index":"index3","_type":"_doc","_id":"id-3","error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index3]","index":"index3"}],"type":"index_not_found_exception","reason":"no such index [index3]","index":"index3"}}]}`,
+		200,
+		nil,
+	)
+
+	client := mustNewClientTest(server)
+	response, err := client.MGet(context.Background(), MGetConfig{
+		Index: "index1",
+		Documents: []MGetDocument{
+			{ID: "id-1"},
+			{Index: "index2", ID: "id-2", Routing: "r1"},
+			{Index: "index3", ID: "id-3"},
+		},
+	})
+	assert.NoError(t, err)
+	require.Len(t, response.Documents, 3)
+
+	documentErr := response.Documents[2].Err
+	assert.ErrorContains(t, documentErr, "[404 Not Found] index_not_found_exception: no such index [index3]")
+	assert.Equal(t, ErrCodeNotFound, errors.GetCode(documentErr))
+	response.Documents[2].Err = nil
+
+	assert.Equal(t, MGetResponse{
+		Documents: []GetResponse{
+			{
+				ID:          "id-1",
+				Index:       "index1",
+				Version:     1,
+				PrimaryTerm: 1,
+				Found:       true,
+				Source:      []byte(`{"Name":"John"}`),
+			},
+			{
+				ID:    "id-2",
+				Index: "index2",
+			},
+			{
+				ID:    "id-3",
+				Index: "index3",
+			},
+		},
+	}, response)
+}
+
+func Test_Index(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		config            IndexConfig
+		transport         *mockTransport
+		expectedResponse  WriteResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: IndexConfig{
+				Index:         "index1",
+				ID:            "id-1",
+				Document:      map[string]string{"Name": "John"},
+				OpType:        OpTypeIndex,
+				IfSeqNo:       ref.Of(10),
+				IfPrimaryTerm: ref.Of(1),
+				Refresh:       RefreshWaitFor,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_doc/id-1?if_primary_term=1&if_seq_no=10&op_type=index&refresh=wait_for",
+					`{"Name":"John"}`,
+				).Once().Return(
+					`{"_index":"index1","_type":"_doc","_id":"id-1","_version":4,"result":"updated","_shards":{"total":2,"successful":2,"failed":0},"_seq_no":11,"_primary_term":1}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: WriteResponse{
+				ID:          "id-1",
+				Index:       "index1",
+				Version:     4,
+				SeqNo:       11,
+				PrimaryTerm: 1,
+				Result:      "updated",
+			},
+		},
+		{
+			name: "version conflict",
+			config: IndexConfig{
+				Index:    "index1",
+				ID:       "id-1",
+				Document: []byte(`{"Name":"John"}`),
+				OpType:   OpTypeCreate,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_doc/id-1?op_type=create",
+					`{"Name":"John"}`,
+				).Once().Return(
+					`{"error":{"root_cause":[{"type":"version_conflict_engine_exception","reason":"[id-1]: version conflict, document already exists (current version [4])"}],"type":"version_conflict_engine_exception","reason":"[id-1]: version conflict, document already exists (current version [4])"},"status":409}`,
+					409,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.Client.Index: doIndex: [409 Conflict] version_conflict_engine_exception: [id-1]: version conflict, document already exists (current version [4]): [id-1]: version conflict, document already exists (current version [4])",
+			expectedErrorCode: ErrCodeConflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := client.Index(context.Background(), test.config)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+		})
+	}
+}
+
+func Test_Update(t *testing.T) {
+	t.Parallel()
+
+	server := new(mockTransport)
+	server.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_doc/id-1/_update?retry_on_conflict=3",
+		`{"script":{"params":{"count":1},"source":"ctx._source.counter += params.count"},"upsert":{"counter":1}}`,
+	).Once().Return(
+		`{"_index":"index1","_type":"_doc","_id":"id-1","_version":2,"result":"updated","_shards":{"total":2,"successful":2,"failed":0},"_seq_no":5,"_primary_term":1}`,
+		200,
+		nil,
+	)
+
+	client := mustNewClientTest(server)
+	response, err := client.Update(context.Background(), UpdateConfig{
+		Index:           "index1",
+		ID:              "id-1",
+		Script:          querybuilders.NewScript("ctx._source.counter += params.count").Param("count", 1),
+		Upsert:          map[string]int{"counter": 1},
+		RetryOnConflict: 3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, WriteResponse{
+		ID:          "id-1",
+		Index:       "index1",
+		Version:     2,
+		SeqNo:       5,
+		PrimaryTerm: 1,
+		Result:      "updated",
+	}, response)
+}
+
+func Test_Delete(t *testing.T) {
+	t.Parallel()
+
+	server := new(mockTransport)
+	server.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_doc/id-1",
+		"",
+	).Once().Return(
+		`{"_index":"index1","_type":"_doc","_id":"id-1","_version":1,"result":"not_found","_shards":{"total":2,"successful":2,"failed":0},"_seq_no":6,"_primary_term":1}`,
+		404,
+		nil,
+	)
+
+	client := mustNewClientTest(server)
+	response, err := client.Delete(context.Background(), DeleteConfig{
+		Index: "index1",
+		ID:    "id-1",
+	})
+	assert.EqualError(t, err, "v7.Client.Delete: doDelete: document not found")
+	assert.Equal(t, ErrCodeNotFound, errors.GetCode(err))
+	assert.Equal(t, WriteResponse{}, response)
+}
@@ -0,0 +1,124 @@
+package v7
+
+import (
+	"context"
+	"io"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// UpdateConfig hold all information to Update method.
+//
+// Either Doc, for a partial update, or Script must be set. Upsert is the
+// document indexed when the document does not exist yet; alternatively,
+// DocAsUpsert uses Doc itself as the upsert document.
+type UpdateConfig struct {
+	Index           string
+	ID              string
+	Doc             interface{}
+	Upsert          interface{}
+	DocAsUpsert     bool
+	Script          *querybuilders.Script
+	ScriptedUpsert  bool
+	RetryOnConflict int
+	IfSeqNo         *int
+	IfPrimaryTerm   *int
+	Routing         string
+	Refresh         Refresh
+}
+
+// Update partially updates the document identified by @config's Index and ID.
+func (c *esClient) Update(ctx context.Context, config UpdateConfig) (WriteResponse, error) {
+	const op = errors.Op("v7.Client.Update")
+
+	enrichLogWithIndexes(ctx, []string{config.Index})
+
+	response, err := c.doUpdate(ctx, config)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseWriteResponse(response)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doUpdate(ctx context.Context, config UpdateConfig) (*esapi.Response, error) {
+	const op = errors.Op("doUpdate")
+
+	if config.Index == "" {
+		return nil, errors.E(op, requiredFieldError("Index"), ErrCodeBadRequest)
+	}
+	if config.ID == "" {
+		return nil, errors.E(op, requiredFieldError("ID"), ErrCodeBadRequest)
+	}
+
+	body, err := getUpdateBody(config)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.UpdateRequest){
+		c.client.Update.WithContext(ctx),
+	}
+	if config.RetryOnConflict > 0 {
+		options = append(options, c.client.Update.WithRetryOnConflict(config.RetryOnConflict))
+	}
+	if config.IfSeqNo != nil {
+		options = append(options, c.client.Update.WithIfSeqNo(*config.IfSeqNo))
+	}
+	if config.IfPrimaryTerm != nil {
+		options = append(options, c.client.Update.WithIfPrimaryTerm(*config.IfPrimaryTerm))
+	}
+	if config.Routing != "" {
+		options = append(options, c.client.Update.WithRouting(config.Routing))
+	}
+	if config.Refresh != "" {
+		options = append(options, c.client.Update.WithRefresh(string(config.Refresh)))
+	}
+
+	response, err := c.client.Update(config.Index, config.ID, body, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	return checkDocumentResponse(op, response)
+}
+
+func getUpdateBody(config UpdateConfig) (io.Reader, error) {
+	const op = errors.Op("getUpdateBody")
+
+	if config.Doc == nil && config.Script == nil {
+		return nil, errors.E(op, requiredFieldError("Doc or Script"))
+	}
+
+	body := make(map[string]interface{})
+	if config.Doc != nil {
+		body["doc"] = config.Doc
+	}
+	if config.DocAsUpsert {
+		body["doc_as_upsert"] = true
+	}
+	if config.Script != nil {
+		script, err := config.Script.Source()
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		body["script"] = script
+	}
+	if config.ScriptedUpsert {
+		body["scripted_upsert"] = true
+	}
+	if config.Upsert != nil {
+		body["upsert"] = config.Upsert
+	}
+
+	return encodeBody(body)
+}
@@ -0,0 +1,114 @@
+package v7
+
+import (
+	"encoding/json"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// BulkAction represents the action of a BulkItem.
+type BulkAction string
+
+const (
+	// BulkActionIndex creates the document or replaces it if it already exists.
+	BulkActionIndex BulkAction = "index"
+	// BulkActionCreate creates the document and fails if it already exists.
+	BulkActionCreate BulkAction = "create"
+	// BulkActionUpdate partially updates the document.
+	BulkActionUpdate BulkAction = "update"
+	// BulkActionDelete removes the document.
+	BulkActionDelete BulkAction = "delete"
+)
+
+// BulkResponse represents the response for Bulk method. Items are in the
+// same order they were requested.
+type BulkResponse struct {
+	Took   int
+	Errors bool
+	Items  []BulkResponseItem
+}
+
+// BulkResponseItem represents the result of a single BulkItem.
+type BulkResponseItem struct {
+	Action      BulkAction
+	ID          string
+	Index       string
+	Version     int
+	SeqNo       int
+	PrimaryTerm int
+	Result      string
+	Status      int
+	Error       *BulkItemError
+}
+
+// BulkItemError represents the error returned by elasticsearch for a
+// single BulkItem.
+type BulkItemError struct {
+	Type   string
+	Reason string
+}
+
+func (e BulkItemError) Error() string {
+	return e.Type + ": " + e.Reason
+}
+
+type envelopeBulk struct {
+	Took   int                             `json:"took"`
+	Errors bool                            `json:"errors"`
+	Items  []map[string]envelopeBulkResult `json:"items"`
+}
+
+type envelopeBulkResult struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int    `json:"_version"`
+	SeqNo       int    `json:"_seq_no"`
+	PrimaryTerm int    `json:"_primary_term"`
+	Result      string `json:"result"`
+	Status      int    `json:"status"`
+	Error       *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+func parseBulkResponse(response *esapi.Response) (BulkResponse, error) {
+	const op = errors.Op("parseBulkResponse")
+
+	var r envelopeBulk
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return BulkResponse{}, errors.E(op, err)
+	}
+
+	bulkResponse := BulkResponse{
+		Took:   r.Took,
+		Errors: r.Errors,
+		Items:  make([]BulkResponseItem, 0, len(r.Items)),
+	}
+
+	for _, item := range r.Items {
+		for action, result := range item {
+			responseItem := BulkResponseItem{
+				Action:      BulkAction(action),
+				ID:          result.ID,
+				Index:       result.Index,
+				Version:     result.Version,
+				SeqNo:       result.SeqNo,
+				PrimaryTerm: result.PrimaryTerm,
+				Result:      result.Result,
+				Status:      result.Status,
+			}
+			if result.Error != nil {
+				responseItem.Error = &BulkItemError{
+					Type:   result.Error.Type,
+					Reason: result.Error.Reason,
+				}
+			}
+			bulkResponse.Items = append(bulkResponse.Items, responseItem)
+		}
+	}
+
+	return bulkResponse, nil
+}
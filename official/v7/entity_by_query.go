@@ -0,0 +1,271 @@
+package v7
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// Conflicts represents what DeleteByQuery and UpdateByQuery do when a
+// document changes while they are running.
+type Conflicts string
+
+const (
+	// ConflictsAbort stops the operation on the first version conflict.
+	// This is the default.
+	ConflictsAbort Conflicts = "abort"
+	// ConflictsProceed counts the version conflicts and goes on.
+	ConflictsProceed Conflicts = "proceed"
+)
+
+// SlicesAuto lets elasticsearch choose the number of slices of DeleteByQuery
+// and UpdateByQuery.
+const SlicesAuto = -1
+
+// ByQueryResponse represents the response for DeleteByQuery and
+// UpdateByQuery methods. If the operation ran asynchronously, only TaskID is
+// set and the result must be read with GetTask or WaitForTask.
+type ByQueryResponse struct {
+	TaskID           string
+	Took             int
+	TimedOut         bool
+	Total            int
+	Updated          int
+	Created          int
+	Deleted          int
+	Batches          int
+	VersionConflicts int
+	Noops            int
+	Failures         []ByQueryFailure
+}
+
+// ByQueryFailure represents a document or shard that failed during a
+// DeleteByQuery or UpdateByQuery.
+type ByQueryFailure struct {
+	Index  string
+	ID     string
+	Status int
+	Type   string
+	Reason string
+}
+
+// ByQueryError is returned by DeleteByQuery and UpdateByQuery when
+// elasticsearch stops the operation and replies with an error status and
+// the operation's result instead of an error, such as a status 409 on the
+// first version conflict with ConflictsAbort. Response holds what was done
+// before it stopped and the Failures. It can be retrieved from the errors
+// returned by the Client using errors.As.
+type ByQueryError struct {
+	Status   int
+	Response ByQueryResponse
+}
+
+func (e *ByQueryError) Error() string {
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteString(" ")
+	b.WriteString(http.StatusText(e.Status))
+	b.WriteString("] ")
+	b.WriteString(strconv.Itoa(len(e.Response.Failures)))
+	b.WriteString(" failures")
+	if len(e.Response.Failures) > 0 {
+		b.WriteString(": ")
+		b.WriteString(e.Response.Failures[0].Type)
+		b.WriteString(": ")
+		b.WriteString(e.Response.Failures[0].Reason)
+	}
+	return b.String()
+}
+
+// Code returns the error code related to the status of the ByQueryError.
+// It returns errors.CodeEmpty if the status has no related code.
+func (e *ByQueryError) Code() errors.Code {
+	return codeFromStatus(e.Status)
+}
+
+type envelopeByQuery struct {
+	Task             string                   `json:"task"`
+	Took             int                      `json:"took"`
+	TimedOut         bool                     `json:"timed_out"`
+	Total            int                      `json:"total"`
+	Updated          int                      `json:"updated"`
+	Created          int                      `json:"created"`
+	Deleted          int                      `json:"deleted"`
+	Batches          int                      `json:"batches"`
+	VersionConflicts int                      `json:"version_conflicts"`
+	Noops            int                      `json:"noops"`
+	Failures         []envelopeByQueryFailure `json:"failures"`
+}
+
+// envelopeByQueryFailure holds both bulk failures, which have a cause, and
+// search failures, which have a reason.
+type envelopeByQueryFailure struct {
+	Index  string              `json:"index"`
+	ID     string              `json:"id"`
+	Status int                 `json:"status"`
+	Cause  *envelopeErrorCause `json:"cause"`
+	Reason *envelopeErrorCause `json:"reason"`
+}
+
+type envelopeErrorCause struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (e envelopeByQuery) toResponse() ByQueryResponse {
+	response := ByQueryResponse{
+		TaskID:           e.Task,
+		Took:             e.Took,
+		TimedOut:         e.TimedOut,
+		Total:            e.Total,
+		Updated:          e.Updated,
+		Created:          e.Created,
+		Deleted:          e.Deleted,
+		Batches:          e.Batches,
+		VersionConflicts: e.VersionConflicts,
+		Noops:            e.Noops,
+	}
+
+	for _, failure := range e.Failures {
+		cause := failure.Cause
+		if cause == nil {
+			cause = failure.Reason
+		}
+		byQueryFailure := ByQueryFailure{
+			Index:  failure.Index,
+			ID:     failure.ID,
+			Status: failure.Status,
+		}
+		if cause != nil {
+			byQueryFailure.Type = cause.Type
+			byQueryFailure.Reason = cause.Reason
+		}
+		response.Failures = append(response.Failures, byQueryFailure)
+	}
+
+	return response
+}
+
+func parseByQueryResponse(response *esapi.Response) (ByQueryResponse, error) {
+	const op = errors.Op("parseByQueryResponse")
+
+	var r envelopeByQuery
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return ByQueryResponse{}, errors.E(op, err)
+	}
+
+	return r.toResponse(), nil
+}
+
+// checkByQueryErrorFromResponse works like checkErrorFromResponse, but it
+// also reads the body elasticsearch replies with when it stops a by query
+// operation, which has its result instead of an error.
+func checkByQueryErrorFromResponse(response *esapi.Response) error {
+	if response == nil || !response.IsError() {
+		return checkErrorFromResponse(response)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	var r struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &r) != nil || len(r.Error) > 0 {
+		response.Body = io.NopCloser(bytes.NewReader(body))
+		return checkErrorFromResponse(response)
+	}
+
+	var e envelopeByQuery
+	err = json.Unmarshal(body, &e)
+	if err != nil {
+		return err
+	}
+
+	byQueryError := &ByQueryError{
+		Status:   response.StatusCode,
+		Response: e.toResponse(),
+	}
+	if code := byQueryError.Code(); code != errors.CodeEmpty {
+		return errors.E(byQueryError, code)
+	}
+	return byQueryError
+}
+
+// getByQueryBody returns the body of a by query operation. An empty @filter
+// matches every document, so it is only accepted if @matchAll is true.
+func getByQueryBody(filter Filter, matchAll bool, script *querybuilders.Script) (io.Reader, error) {
+	const op = errors.Op("getByQueryBody")
+
+	query, err := buildElasticBoolQuery(filter)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	if _, ok := query.(*querybuilders.MatchAllQuery); ok && !matchAll {
+		return nil, errors.E(op, requiredFieldError("Filter"))
+	}
+
+	querySource, err := query.Source()
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	body := map[string]interface{}{
+		"query": querySource,
+	}
+	if script != nil {
+		scriptSource, err := script.Source()
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		body["script"] = scriptSource
+	}
+
+	return encodeBody(body)
+}
+
+// requestsPerSecondTransport sets the requests_per_second parameter of the
+// requests, which esapi only accepts as an integer.
+type requestsPerSecondTransport struct {
+	next              esapi.Transport
+	requestsPerSecond float64
+}
+
+func (t requestsPerSecondTransport) Perform(r *http.Request) (*http.Response, error) {
+	query := r.URL.Query()
+	query.Set("requests_per_second", strconv.FormatFloat(t.requestsPerSecond, 'f', -1, 64))
+	r.URL.RawQuery = query.Encode()
+	return t.next.Perform(r)
+}
+
+// byQueryAPI returns the API used to send a by query operation throttled
+// to @requestsPerSecond, if not nil.
+func (c *esClient) byQueryAPI(requestsPerSecond *float64) *esapi.API {
+	if requestsPerSecond == nil {
+		return c.client.API
+	}
+	return esapi.New(requestsPerSecondTransport{
+		next:              c.client,
+		requestsPerSecond: *requestsPerSecond,
+	})
+}
+
+// slicesParam returns the value of the slices parameter for @slices.
+func slicesParam(slices int) interface{} {
+	if slices == SlicesAuto {
+		return "auto"
+	}
+	return slices
+}
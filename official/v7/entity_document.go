@@ -0,0 +1,245 @@
+package v7
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// OpType represents the operation type used by the Index method.
+type OpType string
+
+const (
+	// OpTypeIndex creates the document or replaces it if it already exists.
+	OpTypeIndex OpType = "index"
+	// OpTypeCreate creates the document and fails if it already exists.
+	OpTypeCreate OpType = "create"
+)
+
+// Refresh represents the refresh policy of write operations.
+type Refresh string
+
+const (
+	// RefreshTrue refreshes the affected shards right after the operation.
+	RefreshTrue Refresh = "true"
+	// RefreshFalse does not refresh the affected shards. This is the default.
+	RefreshFalse Refresh = "false"
+	// RefreshWaitFor waits for a refresh to make the operation visible.
+	RefreshWaitFor Refresh = "wait_for"
+)
+
+// GetResponse represents the response for Get method and for each document
+// of the MGet method.
+//
+// Err is only set on the documents of MGet that elasticsearch failed to get,
+// such as a document of a missing index. It holds an ElasticError and is
+// classified with the ErrCode* codes.
+type GetResponse struct {
+	ID          string
+	Index       string
+	Version     int
+	SeqNo       int
+	PrimaryTerm int
+	Found       bool
+	Source      json.RawMessage
+	Fields      map[string][]interface{}
+	Err         error
+}
+
+// MGetResponse represents the response for MGet method. Documents are in the
+// same order they were requested, and a document that failed has its Err
+// set instead of failing the whole MGet.
+type MGetResponse struct {
+	Documents []GetResponse
+}
+
+// WriteResponse represents the response for Index, Update and Delete methods.
+type WriteResponse struct {
+	ID          string
+	Index       string
+	Version     int
+	SeqNo       int
+	PrimaryTerm int
+	Result      string
+}
+
+type envelopeGet struct {
+	Index       string                   `json:"_index"`
+	ID          string                   `json:"_id"`
+	Version     int                      `json:"_version"`
+	SeqNo       int                      `json:"_seq_no"`
+	PrimaryTerm int                      `json:"_primary_term"`
+	Found       bool                     `json:"found"`
+	Source      json.RawMessage          `json:"_source"`
+	Fields      map[string][]interface{} `json:"fields"`
+	Error       json.RawMessage          `json:"error"`
+}
+
+type envelopeMGet struct {
+	Docs []envelopeGet `json:"docs"`
+}
+
+type envelopeWrite struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int    `json:"_version"`
+	SeqNo       int    `json:"_seq_no"`
+	PrimaryTerm int    `json:"_primary_term"`
+	Result      string `json:"result"`
+}
+
+func (e envelopeGet) toResponse() GetResponse {
+	return GetResponse{
+		ID:          e.ID,
+		Index:       e.Index,
+		Version:     e.Version,
+		SeqNo:       e.SeqNo,
+		PrimaryTerm: e.PrimaryTerm,
+		Found:       e.Found,
+		Source:      e.Source,
+		Fields:      e.Fields,
+	}
+}
+
+func parseGetResponse(response *esapi.Response) (GetResponse, error) {
+	const op = errors.Op("parseGetResponse")
+
+	var r envelopeGet
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return GetResponse{}, errors.E(op, err)
+	}
+
+	return r.toResponse(), nil
+}
+
+func parseMGetResponse(response *esapi.Response) (MGetResponse, error) {
+	const op = errors.Op("parseMGetResponse")
+
+	var r envelopeMGet
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return MGetResponse{}, errors.E(op, err)
+	}
+
+	documents := make([]GetResponse, 0, len(r.Docs))
+	for _, doc := range r.Docs {
+		document := doc.toResponse()
+		if len(doc.Error) > 0 {
+			document.Err = parseMGetDocumentError(doc)
+		}
+		documents = append(documents, document)
+	}
+
+	return MGetResponse{Documents: documents}, nil
+}
+
+// parseMGetDocumentError parses the error of a single document of _mget.
+// It has no status, so the status is inferred from the error type.
+func parseMGetDocumentError(doc envelopeGet) error {
+	const op = errors.Op("parseMGetDocumentError")
+
+	elasticError, err := parseElasticError(http.StatusInternalServerError, doc.Error)
+	if err != nil {
+		return errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+	elasticError.Status = getErrorTypeStatus(elasticError.Type)
+
+	code := elasticError.Code()
+	if code == errors.CodeEmpty {
+		code = ErrCodeBadGateway
+	}
+	return errors.E(
+		op,
+		elasticError,
+		code,
+		errors.KV("index", doc.Index),
+		errors.KV("id", doc.ID),
+	)
+}
+
+// getErrorTypeStatus returns the status elasticsearch replies with for the
+// errors of @errorType.
+func getErrorTypeStatus(errorType string) int {
+	switch errorType {
+	case "index_not_found_exception":
+		return http.StatusNotFound
+	case "illegal_argument_exception", "routing_missing_exception", "action_request_validation_exception":
+		return http.StatusBadRequest
+	case "es_rejected_execution_exception":
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+func parseWriteResponse(response *esapi.Response) (WriteResponse, error) {
+	const op = errors.Op("parseWriteResponse")
+
+	var r envelopeWrite
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return WriteResponse{}, errors.E(op, err)
+	}
+
+	return WriteResponse{
+		ID:          r.ID,
+		Index:       r.Index,
+		Version:     r.Version,
+		SeqNo:       r.SeqNo,
+		PrimaryTerm: r.PrimaryTerm,
+		Result:      r.Result,
+	}, nil
+}
+
+// checkDocumentResponse checks the @response of a document operation,
+// closing its body and classifying the error if elasticsearch failed.
+func checkDocumentResponse(op errors.Op, response *esapi.Response) (*esapi.Response, error) {
+	err := checkDocumentErrorFromResponse(response)
+	if err != nil {
+		if response != nil {
+			response.Body.Close()
+		}
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+// encodeBody returns a reader with the JSON representation of @v. Raw JSON
+// given as []byte or json.RawMessage is used as is.
+func encodeBody(v interface{}) (io.Reader, error) {
+	switch b := v.(type) {
+	case json.RawMessage:
+		return bytes.NewReader(b), nil
+	case []byte:
+		return bytes.NewReader(b), nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(b), nil
+}
+
+// uniqueIndexes returns the non empty @indexes without duplicates, keeping
+// their order.
+func uniqueIndexes(indexes []string) []string {
+	unique := make([]string, 0, len(indexes))
+	seen := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		if index == "" || seen[index] {
+			continue
+		}
+		seen[index] = true
+		unique = append(unique, index)
+	}
+	return unique
+}
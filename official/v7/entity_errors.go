@@ -1,6 +1,12 @@
 package v7
 
-import "github.com/arquivei/foundationkit/errors"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+)
 
 // ---------- Errors
 
@@ -12,12 +18,52 @@ var ErrNotAllShardsReplied = errors.New("not all shards replied")
 // returns a nil response.
 var ErrNilResponse = errors.New("nil response")
 
+// ErrDocumentNotFound is returned when the requested document does not
+// exist in elasticsearch.
+var ErrDocumentNotFound = errors.New("document not found")
+
+// ErrEndOfScroll is returned by ScrollCursor's Next when there are no more
+// pages to be read.
+var ErrEndOfScroll = errors.New("end of scroll")
+
+// ErrInvalidSearchAfter is returned when the SearchAfter of a search is not
+// a JSON array, such as a Paginator edited by hand.
+var ErrInvalidSearchAfter = errors.New("search after must be a JSON array")
+
+// ErrInvalidPaginator is returned when a paginator was not encoded by the
+// client's PaginatorCodec or was edited.
+var ErrInvalidPaginator = errors.New("invalid paginator")
+
+// ErrPaginatorMismatch is returned when a paginator was encoded for a search
+// with another sort or another set of indexes.
+var ErrPaginatorMismatch = errors.New("paginator belongs to another search")
+
+// ErrSearchBeforeWithSearchAfter is returned when a search sets both the
+// SearchBefore and the SearchAfter.
+var ErrSearchBeforeWithSearchAfter = errors.New("search before cannot be used with search after")
+
 // ---------- Codes
 
 // ErrCodeBadRequest is returned when elasticsearch returns a
 // status 400.
 var ErrCodeBadRequest = errors.Code("bad request")
 
+// ErrCodeNotFound is returned when elasticsearch returns a
+// status 404.
+var ErrCodeNotFound = errors.Code("not found")
+
+// ErrCodeConflict is returned when elasticsearch returns a
+// status 409, usually due to a version conflict.
+var ErrCodeConflict = errors.Code("conflict")
+
+// ErrCodeTooManyRequests is returned when elasticsearch returns a
+// status 429, usually due to a rejected execution.
+var ErrCodeTooManyRequests = errors.Code("too many requests")
+
+// ErrCodeServiceUnavailable is returned when elasticsearch returns a
+// status 503.
+var ErrCodeServiceUnavailable = errors.Code("service unavailable")
+
 // ErrCodeBadGateway is returned when elasticsearch client returns an error.
 var ErrCodeBadGateway = errors.Code("bad gateway")
 
@@ -25,6 +71,78 @@ var ErrCodeBadGateway = errors.Code("bad gateway")
 // unexpected data
 var ErrCodeUnexpectedResponse = errors.Code("unexpected response")
 
+// ---------- Elastic Error
+
+// ElasticError represents an error returned by elasticsearch in the body of
+// a response. It can be retrieved from the errors returned by the Client
+// using errors.As.
+type ElasticError struct {
+	Status       int
+	Type         string
+	Reason       string
+	Index        string
+	RootCauses   []ElasticErrorCause
+	CausedBy     *ElasticErrorCause
+	FailedShards []ElasticShardFailure
+}
+
+// ElasticErrorCause represents a root cause or a caused_by of an
+// ElasticError. The CausedBy field holds the next cause in the chain.
+type ElasticErrorCause struct {
+	Type     string
+	Reason   string
+	Index    string
+	CausedBy *ElasticErrorCause
+}
+
+// ElasticShardFailure represents a shard that failed while executing the
+// request.
+type ElasticShardFailure struct {
+	Shard  int
+	Index  string
+	Node   string
+	Reason ElasticErrorCause
+}
+
+func (e *ElasticError) Error() string {
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteString(" ")
+	b.WriteString(http.StatusText(e.Status))
+	b.WriteString("] ")
+	b.WriteString(e.Type)
+	b.WriteString(": ")
+	b.WriteString(e.Reason)
+	if len(e.RootCauses) > 0 {
+		b.WriteString(": ")
+		b.WriteString(e.RootCauses[0].Reason)
+	}
+	return b.String()
+}
+
+// Code returns the error code related to the status of the ElasticError.
+// It returns errors.CodeEmpty if the status has no related code.
+func (e *ElasticError) Code() errors.Code {
+	return codeFromStatus(e.Status)
+}
+
+func codeFromStatus(status int) errors.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrCodeBadRequest
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusTooManyRequests:
+		return ErrCodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return ErrCodeServiceUnavailable
+	}
+	return errors.CodeEmpty
+}
+
 // ----------
 
 func filterMustBeAStructError(kind string) error {
@@ -50,3 +168,7 @@ func multiMatchSearchTypeNotSupported(name string) error {
 func aggregationTypeNotSupported(t string) error {
 	return errors.New("aggregation type is not supported: " + t)
 }
+
+func requiredFieldError(name string) error {
+	return errors.New("[" + name + "] is required")
+}
@@ -0,0 +1,53 @@
+package v7
+
+// SourceFilter controls which parts of the _source are returned for each hit.
+//
+// If Disabled is true, the _source is not returned at all and Includes and
+// Excludes are ignored.
+type SourceFilter struct {
+	Disabled bool
+	Includes []string
+	Excludes []string
+}
+
+// FieldAndFormat represents a field requested through "fields" or
+// "docvalue_fields". Format is optional and is mostly used for date
+// and numeric fields.
+type FieldAndFormat struct {
+	Field  string
+	Format string
+}
+
+func (s SourceFilter) isZero() bool {
+	return !s.Disabled && len(s.Includes) == 0 && len(s.Excludes) == 0
+}
+
+func (s SourceFilter) source() interface{} {
+	if s.Disabled {
+		return false
+	}
+
+	source := make(map[string]interface{})
+	if len(s.Includes) > 0 {
+		source["includes"] = s.Includes
+	}
+	if len(s.Excludes) > 0 {
+		source["excludes"] = s.Excludes
+	}
+	return source
+}
+
+func fieldsAndFormatsSource(fields []FieldAndFormat) []interface{} {
+	source := make([]interface{}, 0, len(fields))
+	for _, field := range fields {
+		if field.Format == "" {
+			source = append(source, field.Field)
+			continue
+		}
+		source = append(source, map[string]interface{}{
+			"field":  field.Field,
+			"format": field.Format,
+		})
+	}
+	return source
+}
@@ -1,9 +1,11 @@
 package v7
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/arquivei/foundationkit/errors"
@@ -13,32 +15,66 @@ import (
 // SearchResponse represents the response for Search method.
 type SearchResponse struct {
 	IDs          []string
+	Hits         []SearchHit
 	Paginator    string
 	Total        int
 	Took         int
 	Aggregations ResponseAggregation
+	// PointInTimeID is the point in time ID to be used in the next search.
+	// It is only set when the search ran on a point in time.
+	PointInTimeID string
+	// ScrollID is the scroll ID to be used to fetch the next page. It is
+	// only set when the search opened or continued a scroll.
+	ScrollID string
+	// PreviousPaginator holds the sort values of the first hit, to be used
+	// as the SearchBefore of the search for the previous page, while
+	// Paginator holds the ones of the last hit, for the next page.
+	PreviousPaginator string
+}
+
+// SearchHit represents a single hit returned by the Search method.
+// The Source field holds the raw _source of the document and Fields holds
+// the values requested through stored_fields, docvalue_fields and fields.
+// Highlight holds the highlighted fragments of each field.
+type SearchHit struct {
+	ID        string
+	Index     string
+	Score     *float64
+	Sort      []interface{}
+	Source    json.RawMessage
+	Fields    map[string][]interface{}
+	Highlight map[string][]string
 }
 
 type envelopeResponse struct {
-	Took int
-	Hits struct {
+	Took     int
+	PitID    string `json:"pit_id"`
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
 		Total struct {
 			Value int
 		}
 		Hits []*envelopeHits `json:"Hits"`
 	}
-	Shards       *shardsInfo            `json:"_shards,omitempty"`
+	Shards       *ShardsInfo            `json:"_shards,omitempty"`
 	Aggregations map[string]interface{} `json:"aggregations"`
 }
 
 type envelopeHits struct {
-	ID   string        `json:"_id"`
-	Sort []interface{} `json:"sort"`
+	ID        string                   `json:"_id"`
+	Index     string                   `json:"_index"`
+	Score     *float64                 `json:"_score"`
+	Sort      []interface{}            `json:"sort"`
+	Source    json.RawMessage          `json:"_source"`
+	Fields    map[string][]interface{} `json:"fields"`
+	Highlight map[string][]string      `json:"highlight"`
 }
 
-type shardsInfo struct {
+// ShardsInfo represents how many shards were involved in a request.
+type ShardsInfo struct {
 	Total      int `json:"total"`
 	Successful int `json:"successful"`
+	Skipped    int `json:"skipped"`
 	Failed     int `json:"failed"`
 }
 
@@ -55,11 +91,15 @@ func parseResponse(ctx context.Context, response *esapi.Response) (SearchRespons
 
 	searchResponse.Total = r.Hits.Total.Value
 	searchResponse.Took = r.Took
+	searchResponse.PointInTimeID = r.PitID
+	searchResponse.ScrollID = r.ScrollID
 
 	enrichLogWithTook(ctx, r.Took)
-	enrichLogWithShards(ctx, getTotalShards(r))
+	enrichLogWithShards(ctx, getTotalShards(r.Shards))
+	recordTook(ctx, r.Took)
+	recordShards(ctx, r.Shards)
 
-	err = checkShards(r)
+	err = checkShards(r.Shards)
 	if err != nil {
 		return searchResponse, errors.E(op, err, ErrCodeBadGateway)
 	}
@@ -75,50 +115,57 @@ func parseResponse(ctx context.Context, response *esapi.Response) (SearchRespons
 
 	for _, hit := range r.Hits.Hits {
 		searchResponse.IDs = append(searchResponse.IDs, hit.ID)
+		searchResponse.Hits = append(searchResponse.Hits, SearchHit{
+			ID:        hit.ID,
+			Index:     hit.Index,
+			Score:     hit.Score,
+			Sort:      hit.Sort,
+			Source:    hit.Source,
+			Fields:    hit.Fields,
+			Highlight: hit.Highlight,
+		})
 	}
 
-	paginator, err := getPaginatorFromHits(r.Hits.Hits)
+	searchResponse.Paginator, err = getPaginatorFromHit(r.Hits.Hits[len(r.Hits.Hits)-1])
 	if err != nil {
 		return SearchResponse{}, errors.E(op, err)
 	}
 
-	searchResponse.Paginator = paginator
+	searchResponse.PreviousPaginator, err = getPaginatorFromHit(r.Hits.Hits[0])
+	if err != nil {
+		return SearchResponse{}, errors.E(op, err)
+	}
 
 	return searchResponse, nil
 }
 
-func getPaginatorFromHits(hits []*envelopeHits) (string, error) {
-	const op errors.Op = "getPaginatorFromHits"
+func getPaginatorFromHit(hit *envelopeHits) (string, error) {
+	const op errors.Op = "getPaginatorFromHit"
 
-	if len(hits) == 0 {
+	if hit == nil || hit.Sort == nil {
 		return "", nil
 	}
-
-	lastHit := hits[len(hits)-1]
-	if lastHit == nil || lastHit.Sort == nil {
-		return "", nil
-	}
-	paginator, err := json.Marshal(lastHit.Sort)
+	paginator, err := json.Marshal(hit.Sort)
 	if err != nil {
 		return "", errors.E(op, err)
 	}
 	return string(paginator), nil
 }
 
-func getTotalShards(r envelopeResponse) int {
-	if r.Shards != nil {
-		return r.Shards.Total
+func getTotalShards(shards *ShardsInfo) int {
+	if shards != nil {
+		return shards.Total
 	}
 	return 0
 }
 
-func checkShards(r envelopeResponse) error {
-	if r.Shards != nil && r.Shards.Failed > 0 {
+func checkShards(shards *ShardsInfo) error {
+	if shards != nil && shards.Failed > 0 {
 		return errors.E(
 			ErrNotAllShardsReplied,
-			errors.KV("replied", r.Shards.Successful),
-			errors.KV("failed", r.Shards.Failed),
-			errors.KV("total", r.Shards.Total),
+			errors.KV("replied", shards.Successful),
+			errors.KV("failed", shards.Failed),
+			errors.KV("total", shards.Total),
 		)
 	}
 	return nil
@@ -133,39 +180,119 @@ func checkErrorFromResponse(response *esapi.Response) error {
 		return nil
 	}
 
-	var responseBody map[string]interface{}
-	decodeError := json.NewDecoder(response.Body).Decode(&responseBody)
+	var r struct {
+		Error json.RawMessage `json:"error"`
+	}
+	decodeError := json.NewDecoder(response.Body).Decode(&r)
 	if decodeError != nil {
 		return decodeError
 	}
 
-	errorBody, ok := responseBody["error"].(map[string]interface{})
-	if !ok {
-		return errors.E("failed to decode error from response", errors.KV("error", responseBody["error"]))
+	elasticError, err := parseElasticError(response.StatusCode, r.Error)
+	if err != nil {
+		return err
 	}
 
-	rootCause, ok := errorBody["root_cause"].([]interface{})
-	if !ok {
-		return errors.New("failed to decode root cause from error response")
+	if code := elasticError.Code(); code != errors.CodeEmpty {
+		return errors.E(elasticError, code)
 	}
+	return elasticError
+}
 
-	var reasonCause interface{}
-	if len(rootCause) > 0 {
-		rootCauses, ok := rootCause[0].(map[string]interface{})
-		if !ok {
-			return errors.New("failed to decode root cause map from error response")
-		}
+type envelopeElasticError struct {
+	Type         string                 `json:"type"`
+	Reason       string                 `json:"reason"`
+	Index        string                 `json:"index"`
+	RootCause    []envelopeElasticCause `json:"root_cause"`
+	CausedBy     *envelopeElasticCause  `json:"caused_by"`
+	FailedShards []struct {
+		Shard  int                  `json:"shard"`
+		Index  string               `json:"index"`
+		Node   string               `json:"node"`
+		Reason envelopeElasticCause `json:"reason"`
+	} `json:"failed_shards"`
+}
 
-		reasonCause = rootCauses["reason"]
+type envelopeElasticCause struct {
+	Type     string                `json:"type"`
+	Reason   string                `json:"reason"`
+	Index    string                `json:"index"`
+	CausedBy *envelopeElasticCause `json:"caused_by"`
+}
+
+func (e *envelopeElasticCause) toCause() *ElasticErrorCause {
+	if e == nil {
+		return nil
+	}
+	return &ElasticErrorCause{
+		Type:     e.Type,
+		Reason:   e.Reason,
+		Index:    e.Index,
+		CausedBy: e.CausedBy.toCause(),
+	}
+}
+
+// parseElasticError parses the error field of an elasticsearch response. It
+// is usually an object, but some APIs reply with a plain string.
+func parseElasticError(status int, raw json.RawMessage) (*ElasticError, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, errors.New("failed to decode error from response")
+	}
+
+	var reason string
+	if json.Unmarshal(raw, &reason) == nil {
+		return &ElasticError{Status: status, Reason: reason}, nil
+	}
+
+	var e envelopeElasticError
+	if json.Unmarshal(raw, &e) != nil {
+		return nil, errors.E("failed to decode error from response", errors.KV("error", string(raw)))
+	}
+
+	elasticError := &ElasticError{
+		Status:   status,
+		Type:     e.Type,
+		Reason:   e.Reason,
+		Index:    e.Index,
+		CausedBy: e.CausedBy.toCause(),
+	}
+	for i := range e.RootCause {
+		elasticError.RootCauses = append(elasticError.RootCauses, *e.RootCause[i].toCause())
+	}
+	for _, shard := range e.FailedShards {
+		elasticError.FailedShards = append(elasticError.FailedShards, ElasticShardFailure{
+			Shard:  shard.Shard,
+			Index:  shard.Index,
+			Node:   shard.Node,
+			Reason: *shard.Reason.toCause(),
+		})
 	}
 
-	err := fmt.Errorf("[%s] %s: %s: %s", response.Status(), errorBody["type"], errorBody["reason"], reasonCause)
+	return elasticError, nil
+}
+
+// checkDocumentErrorFromResponse works like checkErrorFromResponse, but a 404
+// without an error body is reported as ErrDocumentNotFound. This is how
+// elasticsearch replies to Get and Delete of a missing document.
+func checkDocumentErrorFromResponse(response *esapi.Response) error {
+	if response == nil || response.StatusCode != http.StatusNotFound {
+		return checkErrorFromResponse(response)
+	}
 
-	if response.StatusCode == http.StatusBadRequest {
-		err = errors.E(err, ErrCodeBadRequest)
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	var r struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &r) == nil && len(r.Error) == 0 {
+		return errors.E(ErrDocumentNotFound, ErrCodeNotFound)
 	}
 
-	return err
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	return checkErrorFromResponse(response)
 }
 
 func parseAggregations(
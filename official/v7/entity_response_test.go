@@ -2,9 +2,12 @@ package v7
 
 import (
 	"encoding/json"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/r3labs/diff/v3"
 	"github.com/stretchr/testify/assert"
 )
@@ -367,3 +370,112 @@ func Test_parseAggregations(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkErrorFromResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name                 string
+		status               int
+		body                 string
+		expectedError        string
+		expectedErrorCode    errors.Code
+		expectedElasticError *ElasticError
+	}{
+		{
+			name:              "search phase execution exception",
+			status:            400,
+			body:              `{"error":{"root_cause":[{"type":"query_shard_exception","reason":"failed to create query","index":"index1"}],"type":"search_phase_execution_exception","reason":"all shards failed","phase":"query","failed_shards":[{"shard":0,"index":"index1","node":"node-1","reason":{"type":"query_shard_exception","reason":"failed to create query","index":"index1","caused_by":{"type":"number_format_exception","reason":"For input string: \"abc\""}}}],"caused_by":{"type":"number_format_exception","reason":"For input string: \"abc\""}},"status":400}`,
+			expectedError:     "[400 Bad Request] search_phase_execution_exception: all shards failed: failed to create query",
+			expectedErrorCode: ErrCodeBadRequest,
+			expectedElasticError: &ElasticError{
+				Status: 400,
+				Type:   "search_phase_execution_exception",
+				Reason: "all shards failed",
+				RootCauses: []ElasticErrorCause{
+					{Type: "query_shard_exception", Reason: "failed to create query", Index: "index1"},
+				},
+				CausedBy: &ElasticErrorCause{Type: "number_format_exception", Reason: `For input string: "abc"`},
+				FailedShards: []ElasticShardFailure{
+					{
+						Shard: 0,
+						Index: "index1",
+						Node:  "node-1",
+						Reason: ElasticErrorCause{
+							Type:     "query_shard_exception",
+							Reason:   "failed to create query",
+							Index:    "index1",
+							CausedBy: &ElasticErrorCause{Type: "number_format_exception", Reason: `For input string: "abc"`},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:              "index not found",
+			status:            404,
+			body:              `{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index1]","index":"index1"}],"type":"index_not_found_exception","reason":"no such index [index1]","index":"index1"},"status":404}`,
+			expectedError:     "[404 Not Found] index_not_found_exception: no such index [index1]: no such index [index1]",
+			expectedErrorCode: ErrCodeNotFound,
+			expectedElasticError: &ElasticError{
+				Status: 404,
+				Type:   "index_not_found_exception",
+				Reason: "no such index [index1]",
+				Index:  "index1",
+				RootCauses: []ElasticErrorCause{
+					{Type: "index_not_found_exception", Reason: "no such index [index1]", Index: "index1"},
+				},
+			},
+		},
+		{
+			name:              "too many requests",
+			status:            429,
+			body:              `{"error":{"root_cause":[{"type":"es_rejected_execution_exception","reason":"rejected execution"}],"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":429}`,
+			expectedError:     "[429 Too Many Requests] es_rejected_execution_exception: rejected execution: rejected execution",
+			expectedErrorCode: ErrCodeTooManyRequests,
+			expectedElasticError: &ElasticError{
+				Status: 429,
+				Type:   "es_rejected_execution_exception",
+				Reason: "rejected execution",
+				RootCauses: []ElasticErrorCause{
+					{Type: "es_rejected_execution_exception", Reason: "rejected execution"},
+				},
+			},
+		},
+		{
+			name:              "service unavailable with string error",
+			status:            503,
+			body:              `{"error":"cluster is unavailable","status":503}`,
+			expectedError:     "[503 Service Unavailable] : cluster is unavailable",
+			expectedErrorCode: ErrCodeServiceUnavailable,
+			expectedElasticError: &ElasticError{
+				Status: 503,
+				Reason: "cluster is unavailable",
+			},
+		},
+		{
+			name:          "missing error",
+			status:        500,
+			body:          `{"status":500}`,
+			expectedError: "failed to decode error from response",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := checkErrorFromResponse(&esapi.Response{
+				StatusCode: test.status,
+				Body:       io.NopCloser(strings.NewReader(test.body)),
+			})
+			assert.EqualError(t, err, test.expectedError)
+			assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+
+			if test.expectedElasticError != nil {
+				var elasticError *ElasticError
+				if assert.ErrorAs(t, err, &elasticError) {
+					assert.Equal(t, test.expectedElasticError, elasticError)
+				}
+			}
+		})
+	}
+}
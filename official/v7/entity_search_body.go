@@ -0,0 +1,180 @@
+package v7
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/arquivei/foundationkit/errors"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// SearchRequestBody represents the body of a search request. It is
+// marshaled with encoding/json, in the order of its fields, and the zero
+// fields are left out.
+//
+// SearchAfter must be a JSON array, such as a SearchResponse's Paginator.
+// The Search method sends the size, sort and track_total_hits in the URL,
+// so it leaves Sort, Size and TrackTotalHits unset, while MultiSearch sends
+// them in the body.
+type SearchRequestBody struct {
+	Query          querybuilders.Query
+	Aggs           querybuilders.Query
+	SearchAfter    json.RawMessage
+	Sort           Sorters
+	From           int
+	Size           *int
+	TrackTotalHits *bool
+	Source         SourceFilter
+	StoredFields   []string
+	DocValueFields []FieldAndFormat
+	Fields         []FieldAndFormat
+	PointInTime    *PointInTime
+	Highlight      *querybuilders.Highlight
+}
+
+type envelopeSearchRequestBody struct {
+	Query          interface{}     `json:"query,omitempty"`
+	Aggs           interface{}     `json:"aggs,omitempty"`
+	SearchAfter    json.RawMessage `json:"search_after,omitempty"`
+	Sort           []interface{}   `json:"sort,omitempty"`
+	From           int             `json:"from,omitempty"`
+	Size           *int            `json:"size,omitempty"`
+	TrackTotalHits *bool           `json:"track_total_hits,omitempty"`
+	Source         interface{}     `json:"_source,omitempty"`
+	StoredFields   []string        `json:"stored_fields,omitempty"`
+	DocValueFields []interface{}   `json:"docvalue_fields,omitempty"`
+	Fields         []interface{}   `json:"fields,omitempty"`
+	PointInTime    interface{}     `json:"pit,omitempty"`
+	Highlight      interface{}     `json:"highlight,omitempty"`
+}
+
+// newSearchRequestBody returns the body of the search described by @config.
+func newSearchRequestBody(config SearchConfig) (SearchRequestBody, error) {
+	const op = errors.Op("newSearchRequestBody")
+
+	query, err := buildElasticBoolQuery(config.Filter)
+	if err != nil {
+		return SearchRequestBody{}, errors.E(op, err)
+	}
+
+	body := SearchRequestBody{
+		Query:          query,
+		Source:         config.Source,
+		StoredFields:   config.StoredFields,
+		DocValueFields: config.DocValueFields,
+		Fields:         config.Fields,
+		PointInTime:    config.PointInTime,
+		Highlight:      config.Highlight,
+	}
+
+	if hasAggregations(config.Aggregation) {
+		body.Aggs, err = buildElasticAggsQuery(config.Aggregation)
+		if err != nil {
+			return SearchRequestBody{}, errors.E(op, err)
+		}
+	}
+
+	if config.SearchAfter != "" {
+		body.SearchAfter = json.RawMessage(config.SearchAfter)
+		err = checkSearchAfter(body.SearchAfter)
+		if err != nil {
+			return SearchRequestBody{}, errors.E(op, err)
+		}
+	}
+
+	return body, nil
+}
+
+// checkSearchAfter returns ErrInvalidSearchAfter if @searchAfter is not a
+// JSON array.
+func checkSearchAfter(searchAfter json.RawMessage) error {
+	var values []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(searchAfter))
+	decoder.UseNumber()
+	if decoder.Decode(&values) != nil || values == nil || decoder.More() {
+		return ErrInvalidSearchAfter
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON of the search body. It fails with
+// ErrInvalidSearchAfter if SearchAfter is set but is not a JSON array.
+//
+// nolint: cyclop
+func (b SearchRequestBody) MarshalJSON() ([]byte, error) {
+	var envelope envelopeSearchRequestBody
+	var err error
+
+	if b.Query != nil {
+		envelope.Query, err = b.Query.Source()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if b.Aggs != nil {
+		envelope.Aggs, err = b.Aggs.Source()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(b.SearchAfter) > 0 {
+		err = checkSearchAfter(b.SearchAfter)
+		if err != nil {
+			return nil, err
+		}
+		envelope.SearchAfter = b.SearchAfter
+	}
+	if len(b.Sort.Sorters) > 0 {
+		envelope.Sort = b.Sort.source()
+	}
+	envelope.From = b.From
+	envelope.Size = b.Size
+	envelope.TrackTotalHits = b.TrackTotalHits
+	if !b.Source.isZero() {
+		envelope.Source = b.Source.source()
+	}
+	envelope.StoredFields = b.StoredFields
+	if len(b.DocValueFields) > 0 {
+		envelope.DocValueFields = fieldsAndFormatsSource(b.DocValueFields)
+	}
+	if len(b.Fields) > 0 {
+		envelope.Fields = fieldsAndFormatsSource(b.Fields)
+	}
+	if b.PointInTime != nil {
+		envelope.PointInTime = b.PointInTime.source()
+	}
+	if b.Highlight != nil {
+		envelope.Highlight, err = b.Highlight.Source()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(envelope)
+}
+
+// redacted returns a copy of the body with the values of the query, of the
+// highlight query and of the search_after replaced by
+// querybuilders.RedactedValue. Aggregations are redacted if they implement
+// querybuilders.Redactor.
+func (b SearchRequestBody) redacted() SearchRequestBody {
+	if b.Query != nil {
+		b.Query = querybuilders.Redact(b.Query)
+	}
+	if b.Aggs != nil {
+		b.Aggs = querybuilders.Redact(b.Aggs)
+	}
+	if b.Highlight != nil {
+		b.Highlight = b.Highlight.Redacted()
+	}
+
+	var values []interface{}
+	if len(b.SearchAfter) > 0 && json.Unmarshal(b.SearchAfter, &values) == nil {
+		for i := range values {
+			values[i] = querybuilders.RedactedValue
+		}
+		b.SearchAfter, _ = json.Marshal(values)
+	}
+	return b
+}
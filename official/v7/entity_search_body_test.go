@@ -0,0 +1,121 @@
+package v7
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/arquivei/foundationkit/ref"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+	"github.com/arquivei/elasticutil/official/v7/querytest"
+)
+
+func Test_SearchRequestBody_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		body          SearchRequestBody
+		expectedBody  string
+		expectedError error
+	}{
+		{
+			name:         "empty",
+			expectedBody: `{}`,
+		},
+		{
+			name: "all fields",
+			body: SearchRequestBody{
+				Query:          querybuilders.NewTermQuery("Name", "John"),
+				Aggs:           querybuilders.NewAggsQuery().Metric(querybuilders.NewMaxAggregation("max_age", "Age")),
+				SearchAfter:    json.RawMessage(`[1700000000000000123,"id-1"]`),
+				Sort:           Sorters{Sorters: []Sorter{{Field: "Date", Ascending: false}, {Field: "ID", Ascending: true}}},
+				From:           20,
+				Size:           ref.Of(10),
+				TrackTotalHits: ref.Of(true),
+				Source:         SourceFilter{Includes: []string{"Name"}},
+				StoredFields:   []string{"Age"},
+				DocValueFields: []FieldAndFormat{{Field: "Date", Format: "epoch_millis"}},
+				Fields:         []FieldAndFormat{{Field: "Name"}},
+				PointInTime:    &PointInTime{ID: "pit-id", KeepAlive: time.Minute},
+				Highlight:      querybuilders.NewHighlight().Fields(querybuilders.NewHighlighterField("Name")),
+			},
+			expectedBody: `{
+				"query": {"term": {"Name": "John"}},
+				"aggs": {"max_age": {"max": {"field": "Age"}}},
+				"search_after": [1700000000000000123, "id-1"],
+				"sort": [{"Date": {"order": "desc"}}, {"ID": {"order": "asc"}}],
+				"from": 20,
+				"size": 10,
+				"track_total_hits": true,
+				"_source": {"includes": ["Name"]},
+				"stored_fields": ["Age"],
+				"docvalue_fields": [{"field": "Date", "format": "epoch_millis"}],
+				"fields": ["Name"],
+				"pit": {"id": "pit-id", "keep_alive": "60000ms"},
+				"highlight": {"fields": {"Name": {}}}
+			}`,
+		},
+		{
+			name:          "search after is not an array",
+			body:          SearchRequestBody{SearchAfter: json.RawMessage(`{"paginator":1}`)},
+			expectedError: ErrInvalidSearchAfter,
+		},
+		{
+			name:          "search after is not JSON",
+			body:          SearchRequestBody{SearchAfter: json.RawMessage(`[1,`)},
+			expectedError: ErrInvalidSearchAfter,
+		},
+		{
+			name:          "search after with trailing data",
+			body:          SearchRequestBody{SearchAfter: json.RawMessage(`[1] [2]`)},
+			expectedError: ErrInvalidSearchAfter,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			body, err := json.Marshal(test.body)
+			if test.expectedError != nil {
+				assert.True(t, errors.Is(err, test.expectedError), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			querytest.Equal(t, test.expectedBody, body)
+		})
+	}
+}
+
+func Test_SearchRequestBody_redacted(t *testing.T) {
+	t.Parallel()
+
+	body := SearchRequestBody{
+		Query:       querybuilders.NewTermsQuery("Name", "John", "Mary"),
+		SearchAfter: json.RawMessage(`["John",123]`),
+		Highlight: querybuilders.NewHighlight().
+			Field("Name").
+			HighlightQuery(querybuilders.NewTermQuery("Name", "Paul")),
+	}
+
+	redacted, err := json.Marshal(body.redacted())
+	assert.NoError(t, err)
+	querytest.Equal(t, `{
+		"query": {"terms": {"Name": ["?", "?"]}},
+		"search_after": ["?", "?"],
+		"highlight": {"fields": {"Name": {}}, "highlight_query": {"term": {"Name": "?"}}}
+	}`, redacted)
+
+	original, err := json.Marshal(body)
+	assert.NoError(t, err)
+	querytest.Equal(t, `{
+		"query": {"terms": {"Name": ["John", "Mary"]}},
+		"search_after": ["John", 123],
+		"highlight": {"fields": {"Name": {}}, "highlight_query": {"term": {"Name": "Paul"}}}
+	}`, original)
+}
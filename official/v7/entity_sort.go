@@ -26,3 +26,29 @@ func (ss Sorters) Strings() []string {
 	}
 	return response
 }
+
+// source returns the sorters in the format of the "sort" key of a search
+// body.
+func (ss Sorters) source() []interface{} {
+	response := make([]interface{}, 0, len(ss.Sorters))
+	for _, sorter := range ss.Sorters {
+		direction := "asc"
+		if !sorter.Ascending {
+			direction = "desc"
+		}
+		response = append(response, map[string]interface{}{
+			sorter.Field: map[string]interface{}{"order": direction},
+		})
+	}
+	return response
+}
+
+// reversed returns the sorters with every direction reversed.
+func (ss Sorters) reversed() Sorters {
+	sorters := make([]Sorter, 0, len(ss.Sorters))
+	for _, sorter := range ss.Sorters {
+		sorter.Ascending = !sorter.Ascending
+		sorters = append(sorters, sorter)
+	}
+	return Sorters{Sorters: sorters}
+}
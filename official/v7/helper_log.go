@@ -9,7 +9,52 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+const defaultLogQueryMaxLength = 3000
+
+// LogConfig configures which information of each operation the Client
+// adds to the logger and to the contextmap in the context. The zero value
+// logs everything, with the query truncated to 3000 runes.
+type LogConfig struct {
+	DisableIndexes bool
+	DisableQuery   bool
+	DisableTook    bool
+	DisableShards  bool
+	// QueryMaxLength is how many runes of the query are logged. A zero
+	// value uses the default.
+	QueryMaxLength int
+	// RedactQuery replaces the values of the term, terms and multi match
+	// queries built from the Filter, of the highlight query and of the
+	// search_after by querybuilders.RedactedValue. The structure of the
+	// query is preserved. It is also applied to the query added to the
+	// tracing spans, which WithRedactedStatement redacts on its own. Custom
+	// queries are only redacted if they implement querybuilders.Redactor.
+	RedactQuery bool
+}
+
+// WithLogConfig configures the information logged by the Client.
+func WithLogConfig(config LogConfig) Option {
+	return func(o *clientOptions) error {
+		o.logConfig = config
+		return nil
+	}
+}
+
+type logConfigKey struct{}
+
+func withLogConfig(ctx context.Context, config LogConfig) context.Context {
+	return context.WithValue(ctx, logConfigKey{}, config)
+}
+
+func getLogConfig(ctx context.Context) LogConfig {
+	config, _ := ctx.Value(logConfigKey{}).(LogConfig)
+	return config
+}
+
 func enrichLogWithIndexes(ctx context.Context, indexes []string) {
+	if getLogConfig(ctx).DisableIndexes {
+		return
+	}
+
 	log.Ctx(ctx).UpdateContext(func(zc zerolog.Context) zerolog.Context {
 		return zc.Strs("elastic_indexes", indexes)
 	})
@@ -17,19 +62,50 @@ func enrichLogWithIndexes(ctx context.Context, indexes []string) {
 	contextmap.Ctx(ctx).Set("elastic_indexes", indexes)
 }
 
-func enrichLogWithQuery(ctx context.Context, query string) {
+// enrichLogWithQuery logs the @query and records it for the span. When the
+// query must be redacted, it uses the result of @redacted instead, which is
+// only built in this case.
+func enrichLogWithQuery(ctx context.Context, query string, redacted func() string) {
+	config := getLogConfig(ctx)
+	statement := query
+	if config.RedactQuery || getCallInfo(ctx).redactsQuery() {
+		statement = redacted()
+	}
+	recordQuery(ctx, statement)
+	if config.RedactQuery {
+		query = statement
+	}
+
+	if config.DisableQuery {
+		return
+	}
+
+	maxLength := config.QueryMaxLength
+	if maxLength <= 0 {
+		maxLength = defaultLogQueryMaxLength
+	}
+	query = truncate(query, maxLength)
+
 	log.Ctx(ctx).UpdateContext(func(zc zerolog.Context) zerolog.Context {
-		return zc.Str("elastic_query", truncate(query, 3000))
+		return zc.Str("elastic_query", query)
 	})
 }
 
 func enrichLogWithTook(ctx context.Context, took int) {
+	if getLogConfig(ctx).DisableTook {
+		return
+	}
+
 	log.Ctx(ctx).UpdateContext(func(zc zerolog.Context) zerolog.Context {
 		return zc.Dur("elastic_took_internal", time.Duration(took)*time.Millisecond)
 	})
 }
 
 func enrichLogWithShards(ctx context.Context, shards int) {
+	if getLogConfig(ctx).DisableShards {
+		return
+	}
+
 	log.Ctx(ctx).UpdateContext(func(zc zerolog.Context) zerolog.Context {
 		return zc.Int("elastic_shards", shards)
 	})
@@ -0,0 +1,70 @@
+package v7
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getLoggedFields(t *testing.T, config LogConfig, searchConfig SearchConfig) map[string]interface{} {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	ctx := withLogConfig(logger.WithContext(context.Background()), config)
+
+	enrichLogWithIndexes(ctx, searchConfig.Indexes)
+	_, err := getQuery(ctx, searchConfig)
+	require.NoError(t, err)
+
+	zerolog.Ctx(ctx).Info().Send()
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	return fields
+}
+
+func Test_enrichLogWithQuery(t *testing.T) {
+	t.Parallel()
+
+	searchConfig := SearchConfig{
+		Indexes:     []string{"index1"},
+		Filter:      getMockFilter(),
+		SearchAfter: `["John",123]`,
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		fields := getLoggedFields(t, LogConfig{}, searchConfig)
+		assert.Equal(t, []interface{}{"index1"}, fields["elastic_indexes"])
+		assert.Contains(t, fields["elastic_query"], `{"terms":{"Name":["John","Mary"]}}`)
+		assert.Contains(t, fields["elastic_query"], `"search_after":["John",123]`)
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		t.Parallel()
+		fields := getLoggedFields(t, LogConfig{RedactQuery: true}, searchConfig)
+		query := fields["elastic_query"]
+		assert.Contains(t, query, `{"terms":{"Name":["?","?"]}}`)
+		assert.Contains(t, query, `{"term":{"HasCovid":"?"}}`)
+		assert.Contains(t, query, `{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["?"]}}`)
+		assert.Contains(t, query, `{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"?","type":"phrase_prefix"}}`)
+		assert.Contains(t, query, `"search_after":["?","?"]`)
+		for _, value := range []string{"John", "Mary", "cough", "Lennon", "Beatles", "Lary"} {
+			assert.NotContains(t, query, value)
+		}
+	})
+
+	t.Run("disabled and truncated", func(t *testing.T) {
+		t.Parallel()
+		fields := getLoggedFields(t, LogConfig{DisableIndexes: true, QueryMaxLength: 10}, searchConfig)
+		assert.NotContains(t, fields, "elastic_indexes")
+		assert.Equal(t, `{"query":{`, fields["elastic_query"])
+
+		fields = getLoggedFields(t, LogConfig{DisableQuery: true}, searchConfig)
+		assert.NotContains(t, fields, "elastic_query")
+	})
+}
@@ -0,0 +1,54 @@
+package v7
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics receives the measurements of every Client operation. It is
+// called by the Client returned by NewClientWithOptions when the
+// WithMetrics option is set, and must be safe for concurrent use.
+//
+// Implementations usually adapt the calls to counters and histograms of a
+// metrics library, labelled by MetricsRequest's Operation and Indexes.
+type Metrics interface {
+	// RequestStarted is called before the operation is started.
+	RequestStarted(ctx context.Context, request MetricsRequest)
+	// RequestFinished is called after the operation is finished, even if
+	// it failed.
+	RequestFinished(ctx context.Context, request MetricsRequest, result MetricsResult)
+}
+
+// MetricsRequest identifies the operation being measured.
+type MetricsRequest struct {
+	// Operation is the name of the Client method, such as Search, or of
+	// the ScrollCursor method, such as ScrollCursor.Next.
+	Operation string
+	Indexes   []string
+}
+
+// MetricsResult represents the measurements of a finished operation.
+//
+// StatusCode is the status of the last response received from
+// elasticsearch and ResponseBytes sums the bytes read from all responses.
+// Both are zero when no response was received. Took and ShardsFailed are
+// only set by the operations whose responses have them. Retries counts the
+// retries made by the RetryPolicy.
+type MetricsResult struct {
+	Latency       time.Duration
+	Took          time.Duration
+	StatusCode    int
+	ShardsFailed  int
+	Retries       int
+	ResponseBytes int64
+	Err           error
+}
+
+// WithMetrics makes the Client report the measurements of every operation
+// to @metrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *clientOptions) error {
+		o.metrics = metrics
+		return nil
+	}
+}
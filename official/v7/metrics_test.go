@@ -0,0 +1,128 @@
+package v7
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedMetrics struct {
+	mu       sync.Mutex
+	started  []MetricsRequest
+	finished []MetricsResult
+}
+
+func (m *recordedMetrics) RequestStarted(_ context.Context, request MetricsRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started = append(m.started, request)
+}
+
+func (m *recordedMetrics) RequestFinished(_ context.Context, _ MetricsRequest, result MetricsResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finished = append(m.finished, result)
+}
+
+func Test_WithMetrics(t *testing.T) {
+	t.Parallel()
+
+	tooManyRequests := `{"error":{"root_cause":[{"type":"es_rejected_execution_exception","reason":"rejected execution"}],"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":429}`
+	success := `{"took":12,"_shards":{"total":3,"successful":2,"skipped":0,"failed":0},"hits":{"total":{"value":1},"hits":[{"_index":"index1","_id":"id-1"}]}}`
+
+	searches := 0
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		body := `{}`
+		status := 200
+		if r.URL.Path == "/index1/_search" {
+			searches++
+			body = success
+			if searches == 1 {
+				body = tooManyRequests
+				status = 429
+			}
+		}
+		response := &http.Response{
+			StatusCode: status,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		response.Header.Add("X-Elastic-Product", "Elasticsearch")
+		return response, nil
+	})
+
+	metrics := new(recordedMetrics)
+	client, err := NewClientWithOptions(
+		[]string{"http://localhost:9200"},
+		WithTransport(transport),
+		WithMetrics(metrics),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			RetryOnStatus:  []int{429},
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), SearchConfig{
+		Indexes: []string{"index1"},
+		Size:    1,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []MetricsRequest{{Operation: "Search", Indexes: []string{"index1"}}}, metrics.started)
+	require.Len(t, metrics.finished, 1)
+
+	result := metrics.finished[0]
+	assert.Greater(t, result.Latency, time.Duration(0))
+	result.Latency = 0
+	assert.Equal(t, MetricsResult{
+		Took:          12 * time.Millisecond,
+		StatusCode:    200,
+		Retries:       1,
+		ResponseBytes: int64(len(tooManyRequests) + len(success)),
+	}, result)
+}
+
+func Test_WithMetrics_Error(t *testing.T) {
+	t.Parallel()
+
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		body := `{}`
+		status := 200
+		if r.URL.Path == "/index1/_doc/id-1" {
+			body = `{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index1]"}],"type":"index_not_found_exception","reason":"no such index [index1]"},"status":404}`
+			status = 404
+		}
+		response := &http.Response{
+			StatusCode: status,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		response.Header.Add("X-Elastic-Product", "Elasticsearch")
+		return response, nil
+	})
+
+	metrics := new(recordedMetrics)
+	client, err := NewClientWithOptions(
+		[]string{"http://localhost:9200"},
+		WithTransport(transport),
+		WithMetrics(metrics),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), GetConfig{Index: "index1", ID: "id-1"})
+	require.Error(t, err)
+
+	assert.Equal(t, []MetricsRequest{{Operation: "Get", Indexes: []string{"index1"}}}, metrics.started)
+	require.Len(t, metrics.finished, 1)
+	assert.Equal(t, 404, metrics.finished[0].StatusCode)
+	assert.Equal(t, err, metrics.finished[0].Err)
+}
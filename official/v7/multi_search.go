@@ -0,0 +1,300 @@
+package v7
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// MultiSearchResponse represents the response for MultiSearch method.
+//
+// Responses and Errors have one entry for each requested SearchConfig, in
+// the same order. When a search fails, its error is set in Errors and its
+// response is empty.
+type MultiSearchResponse struct {
+	Took      int
+	Responses []SearchResponse
+	Errors    []error
+}
+
+type envelopeMultiSearch struct {
+	Took      int               `json:"took"`
+	Responses []json.RawMessage `json:"responses"`
+}
+
+// multiSearchItem is a search of a MultiSearch that is sent to
+// elasticsearch. Position is its index in the requested configs, and config
+// is the requested SearchConfig with its paginators decoded.
+type multiSearchItem struct {
+	position     int
+	config       SearchConfig
+	previousPage bool
+	header       []byte
+	body         SearchRequestBody
+	query        []byte
+}
+
+// MultiSearch runs all @configs in a single _msearch request. A failure of a
+// single search, including an invalid SearchConfig, does not fail the
+// request and is reported in the response's Errors.
+func (c *esClient) MultiSearch(ctx context.Context, configs []SearchConfig) (MultiSearchResponse, error) {
+	const op = errors.Op("v7.Client.MultiSearch")
+
+	if len(configs) == 0 {
+		return MultiSearchResponse{}, errors.E(op, requiredFieldError("configs"), ErrCodeBadRequest)
+	}
+
+	enrichLogWithIndexes(ctx, getMultiSearchIndexes(configs))
+
+	multiSearchResponse := MultiSearchResponse{
+		Responses: make([]SearchResponse, len(configs)),
+		Errors:    make([]error, len(configs)),
+	}
+	items := make([]multiSearchItem, 0, len(configs))
+	for i, config := range configs {
+		item, err := c.newMultiSearchItem(i, config)
+		if err != nil {
+			multiSearchResponse.Errors[i] = errors.E(op, err, errors.KV("search", i))
+			continue
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return multiSearchResponse, nil
+	}
+
+	var parsedResponse MultiSearchResponse
+	err := c.retryPolicy.do(ctx, func() error {
+		var err error
+		parsedResponse, err = c.multiSearch(ctx, items)
+		return err
+	})
+	if err != nil {
+		return MultiSearchResponse{}, errors.E(op, err)
+	}
+
+	multiSearchResponse.Took = parsedResponse.Took
+	for j, item := range items {
+		i := item.position
+		if parsedResponse.Errors[j] != nil {
+			multiSearchResponse.Errors[i] = errors.E(parsedResponse.Errors[j], errors.KV("search", i))
+			continue
+		}
+
+		response := parsedResponse.Responses[j]
+		if item.previousPage {
+			response = reverseSearchResponse(response)
+		}
+		response, err = c.encodePaginator(item.config, response)
+		if err != nil {
+			multiSearchResponse.Errors[i] = errors.E(op, err, errors.KV("search", i))
+			continue
+		}
+		multiSearchResponse.Responses[i] = response
+	}
+
+	return multiSearchResponse, nil
+}
+
+// multiSearch runs the @items. Only the failure of the whole request is
+// returned, the failures of single searches are not retried.
+func (c *esClient) multiSearch(ctx context.Context, items []multiSearchItem) (MultiSearchResponse, error) {
+	response, err := c.doMultiSearch(ctx, items)
+	if err != nil {
+		return MultiSearchResponse{}, err
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseMultiSearchResponse(ctx, response, len(items))
+	if err != nil {
+		return MultiSearchResponse{}, errors.E(err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+// newMultiSearchItem prepares the search of the @config at @position.
+// Parameters sent in the URL by the Search method go in the header or in
+// the body, as _msearch only accepts them there.
+func (c *esClient) newMultiSearchItem(position int, config SearchConfig) (multiSearchItem, error) {
+	config, err := c.decodePaginator(config)
+	if err != nil {
+		return multiSearchItem{}, err
+	}
+
+	searchConfig, previousPage, err := previousPageConfig(config)
+	if err != nil {
+		return multiSearchItem{}, errors.E(err, ErrCodeBadRequest)
+	}
+
+	header := make(map[string]interface{})
+	if searchConfig.PointInTime == nil {
+		if len(searchConfig.Indexes) > 0 {
+			header["index"] = searchConfig.Indexes
+		}
+		header["ignore_unavailable"] = searchConfig.IgnoreUnavailable
+		header["allow_no_indices"] = searchConfig.AllowNoIndices
+	}
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return multiSearchItem{}, errors.E(err, ErrCodeBadRequest)
+	}
+
+	body, err := newSearchRequestBody(searchConfig)
+	if err != nil {
+		return multiSearchItem{}, errors.E(err, ErrCodeBadRequest)
+	}
+	body.Size = &searchConfig.Size
+	body.TrackTotalHits = &searchConfig.TrackTotalHits
+	body.Sort = searchConfig.Sort
+
+	query, err := json.Marshal(body)
+	if err != nil {
+		return multiSearchItem{}, errors.E(err, ErrCodeBadRequest)
+	}
+
+	return multiSearchItem{
+		position:     position,
+		config:       config,
+		previousPage: previousPage,
+		header:       encodedHeader,
+		body:         body,
+		query:        query,
+	}, nil
+}
+
+func (c *esClient) doMultiSearch(ctx context.Context, items []multiSearchItem) (*esapi.Response, error) {
+	const op = errors.Op("doMultiSearch")
+
+	response, err := c.client.Msearch(
+		getMultiSearchBody(ctx, items),
+		c.client.Msearch.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+// getMultiSearchBody returns the header and body lines of the @items and
+// logs their queries together, as a JSON array.
+func getMultiSearchBody(ctx context.Context, items []multiSearchItem) io.Reader {
+	var b bytes.Buffer
+	queries := make([]string, 0, len(items))
+	for _, item := range items {
+		b.Write(item.header)
+		b.WriteByte('\n')
+		b.Write(item.query)
+		b.WriteByte('\n')
+		queries = append(queries, string(item.query))
+	}
+
+	enrichLogWithQuery(ctx, "["+strings.Join(queries, ",")+"]", func() string {
+		redacted := make([]string, 0, len(items))
+		for _, item := range items {
+			redacted = append(redacted, marshalValue(item.body.redacted()))
+		}
+		return "[" + strings.Join(redacted, ",") + "]"
+	})
+
+	return &b
+}
+
+func parseMultiSearchResponse(
+	ctx context.Context,
+	response *esapi.Response,
+	expectedResponses int,
+) (MultiSearchResponse, error) {
+	const op = errors.Op("parseMultiSearchResponse")
+
+	var r envelopeMultiSearch
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return MultiSearchResponse{}, errors.E(op, err)
+	}
+
+	if len(r.Responses) != expectedResponses {
+		return MultiSearchResponse{}, errors.E(
+			op,
+			"unexpected number of responses",
+			errors.KV("expected", expectedResponses),
+			errors.KV("given", len(r.Responses)),
+		)
+	}
+
+	multiSearchResponse := MultiSearchResponse{
+		Took:      r.Took,
+		Responses: make([]SearchResponse, len(r.Responses)),
+		Errors:    make([]error, len(r.Responses)),
+	}
+	for i, raw := range r.Responses {
+		searchResponse, err := parseMultiSearchItem(ctx, raw)
+		if err != nil {
+			multiSearchResponse.Errors[i] = errors.E(op, err)
+			continue
+		}
+		multiSearchResponse.Responses[i] = searchResponse
+	}
+	recordTook(ctx, r.Took)
+
+	return multiSearchResponse, nil
+}
+
+// parseMultiSearchItem parses a single response of _msearch, which has the
+// same format of a _search response, or of an error response, with its own
+// status.
+func parseMultiSearchItem(ctx context.Context, raw json.RawMessage) (SearchResponse, error) {
+	var status struct {
+		Status int `json:"status"`
+	}
+	err := json.Unmarshal(raw, &status)
+	if err != nil {
+		return SearchResponse{}, errors.E(err, ErrCodeUnexpectedResponse)
+	}
+
+	response := &esapi.Response{
+		StatusCode: status.Status,
+		Body:       io.NopCloser(bytes.NewReader(raw)),
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return SearchResponse{}, err
+	}
+
+	searchResponse, err := parseResponse(ctx, response)
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeUnexpectedResponse)
+		}
+		return SearchResponse{}, err
+	}
+
+	return searchResponse, nil
+}
+
+func getMultiSearchIndexes(configs []SearchConfig) []string {
+	var indexes []string
+	for _, config := range configs {
+		indexes = append(indexes, config.Indexes...)
+	}
+	return uniqueIndexes(indexes)
+}
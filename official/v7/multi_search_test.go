@@ -0,0 +1,202 @@
+package v7
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arquivei/elasticutil/official/v7/querytest"
+)
+
+func Test_MultiSearch(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		configs           []SearchConfig
+		transport         *mockTransport
+		expectedIDs       [][]string
+		expectedErrors    []string
+		expectedCodes     []errors.Code
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success with a failed search",
+			configs: []SearchConfig{
+				{
+					Indexes:        []string{"index1"},
+					Size:           2,
+					Filter:         Filter{},
+					TrackTotalHits: true,
+					Sort: Sorters{
+						Sorters: []Sorter{{Field: "Date", Ascending: false}},
+					},
+					SearchAfter: `[1700000000000000123]`,
+				},
+				{
+					Indexes: []string{"index2"},
+					Size:    1,
+					Filter:  Filter{},
+				},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_msearch",
+					`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index1"]}`+"\n"+
+						`{"query":{"match_all":{}},"search_after":[1700000000000000123],"sort":[{"Date":{"order":"desc"}}],"size":2,"track_total_hits":true}`+"\n"+
+						`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index2"]}`+"\n"+
+						`{"query":{"match_all":{}},"size":1,"track_total_hits":false}`+"\n",
+				).Once().Return(
+					`{"took":3,"responses":[{"took":2,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":5},"hits":[{"_id":"id-1","sort":[1]},{"_id":"id-2","sort":[2]}]},"status":200},{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [index2]"}],"type":"index_not_found_exception","reason":"no such index [index2]"},"status":404}]}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedIDs: [][]string{{"id-1", "id-2"}, nil},
+			expectedErrors: []string{
+				"",
+				"parseMultiSearchResponse: [404 Not Found] index_not_found_exception: no such index [index2]: no such index [index2]",
+			},
+			expectedCodes: []errors.Code{errors.CodeEmpty, ErrCodeNotFound},
+		},
+		{
+			name: "invalid search",
+			configs: []SearchConfig{
+				{
+					Indexes: []string{"index1"},
+					Filter:  Filter{Must: 1},
+				},
+				{
+					Indexes:     []string{"index1"},
+					SearchAfter: `{"paginator":1}`,
+				},
+				{
+					Indexes: []string{"index2"},
+					Size:    1,
+				},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_msearch",
+					`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index2"]}`+"\n"+
+						`{"query":{"match_all":{}},"size":1,"track_total_hits":false}`+"\n",
+				).Once().Return(
+					`{"took":3,"responses":[{"took":2,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":1},"hits":[{"_id":"id-3"}]},"status":200}]}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedIDs: [][]string{nil, nil, {"id-3"}},
+			expectedErrors: []string{
+				"[int] filter must be a struct",
+				ErrInvalidSearchAfter.Error(),
+				"",
+			},
+			expectedCodes: []errors.Code{ErrCodeBadRequest, ErrCodeBadRequest, errors.CodeEmpty},
+		},
+		{
+			name: "only invalid searches",
+			configs: []SearchConfig{
+				{
+					Indexes: []string{"index1"},
+					Filter:  Filter{Must: 1},
+				},
+			},
+			transport:      new(mockTransport),
+			expectedIDs:    [][]string{nil},
+			expectedErrors: []string{"[int] filter must be a struct"},
+			expectedCodes:  []errors.Code{ErrCodeBadRequest},
+		},
+		{
+			name:              "missing configs",
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.MultiSearch: [configs] is required",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := client.MultiSearch(context.Background(), test.configs)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+
+			var ids [][]string
+			for _, r := range response.Responses {
+				ids = append(ids, r.IDs)
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+
+			assert.Len(t, response.Errors, len(test.expectedErrors))
+			for i, e := range response.Errors {
+				if test.expectedErrors[i] == "" {
+					assert.NoError(t, e)
+					continue
+				}
+				assert.ErrorContains(t, e, test.expectedErrors[i])
+				assert.Equal(t, test.expectedCodes[i], errors.GetCode(e))
+			}
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_MultiSearch_logsAllQueries(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/_msearch",
+		`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index1"]}`+"\n"+
+			`{"query":{"terms":{"Name":["John"]}},"size":0,"track_total_hits":false}`+"\n"+
+			`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index2"]}`+"\n"+
+			`{"query":{"terms":{"Name":["Mary"]}},"size":0,"track_total_hits":false}`+"\n",
+	).Once().Return(
+		`{"took":3,"responses":[{"hits":{"hits":[]},"status":200},{"hits":{"hits":[]},"status":200}]}`,
+		200,
+		nil,
+	)
+	defer transport.AssertExpectations(t)
+
+	type nameFilter struct {
+		Names []string `es:"Name"`
+	}
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	ctx := logger.WithContext(context.Background())
+
+	client := mustNewClientTest(transport)
+	_, err := client.MultiSearch(ctx, []SearchConfig{
+		{Indexes: []string{"index1"}, Filter: Filter{Must: nameFilter{Names: []string{"John"}}}},
+		{Indexes: []string{"index2"}, Filter: Filter{Must: nameFilter{Names: []string{"Mary"}}}},
+	})
+	require.NoError(t, err)
+
+	zerolog.Ctx(ctx).Info().Send()
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	querytest.Equal(
+		t,
+		`[{"query":{"terms":{"Name":["John"]}},"size":0,"track_total_hits":false},{"query":{"terms":{"Name":["Mary"]}},"size":0,"track_total_hits":false}]`,
+		fields["elastic_query"],
+	)
+}
@@ -0,0 +1,163 @@
+package v7
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"sort"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+const (
+	paginatorFingerprintSize = 16
+	paginatorSignatureSize   = sha256.Size
+)
+
+// PaginatorCodec turns the sort values of the last hit of a search into an
+// opaque paginator that can be handed to API clients, and back.
+//
+// A paginator is the base64url encoding of a fingerprint of the search's
+// Sort and Indexes, the sort values and an HMAC-SHA256 signature of both.
+// A paginator that was edited, or that was created by a search with another
+// Sort or Indexes, is rejected. The sort values are signed, not encrypted.
+type PaginatorCodec struct {
+	key []byte
+}
+
+// NewPaginatorCodec returns a PaginatorCodec that signs the paginators with
+// the @key, which should have at least 32 random bytes and must be shared
+// by every instance that decodes the paginators.
+func NewPaginatorCodec(key []byte) (*PaginatorCodec, error) {
+	const op = errors.Op("v7.NewPaginatorCodec")
+
+	if len(key) == 0 {
+		return nil, errors.E(op, requiredFieldError("key"))
+	}
+
+	return &PaginatorCodec{key: bytes.Clone(key)}, nil
+}
+
+// WithPaginatorCodec makes Search and MultiSearch return the paginators
+// encoded by the @codec and accept only SearchAfter and SearchBefore values
+// encoded by it.
+func WithPaginatorCodec(codec *PaginatorCodec) Option {
+	return func(o *clientOptions) error {
+		o.paginatorCodec = codec
+		return nil
+	}
+}
+
+// Encode returns the paginator for the @searchAfter, the JSON array of sort
+// values of the last hit of a search described by @config.
+func (c *PaginatorCodec) Encode(config SearchConfig, searchAfter string) (string, error) {
+	const op = errors.Op("v7.PaginatorCodec.Encode")
+
+	err := checkSearchAfter([]byte(searchAfter))
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+
+	payload := append(paginatorFingerprint(config), searchAfter...)
+	token := append(payload, c.sign(payload)...)
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Decode returns the sort values of the @paginator, to be used as the
+// SearchAfter of the search described by @config. It fails with
+// ErrInvalidPaginator if the @paginator was not encoded by this codec and
+// with ErrPaginatorMismatch if it was encoded for a search with another
+// Sort or Indexes.
+func (c *PaginatorCodec) Decode(config SearchConfig, paginator string) (string, error) {
+	const op = errors.Op("v7.PaginatorCodec.Decode")
+
+	token, err := base64.RawURLEncoding.DecodeString(paginator)
+	if err != nil || len(token) <= paginatorFingerprintSize+paginatorSignatureSize {
+		return "", errors.E(op, ErrInvalidPaginator)
+	}
+
+	payload := token[:len(token)-paginatorSignatureSize]
+	signature := token[len(token)-paginatorSignatureSize:]
+	if !hmac.Equal(signature, c.sign(payload)) {
+		return "", errors.E(op, ErrInvalidPaginator)
+	}
+
+	fingerprint := payload[:paginatorFingerprintSize]
+	if !hmac.Equal(fingerprint, paginatorFingerprint(config)) {
+		return "", errors.E(op, ErrPaginatorMismatch)
+	}
+
+	searchAfter := payload[paginatorFingerprintSize:]
+	err = checkSearchAfter(searchAfter)
+	if err != nil {
+		return "", errors.E(op, ErrInvalidPaginator)
+	}
+	return string(searchAfter), nil
+}
+
+func (c *PaginatorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// paginatorFingerprint returns a digest of the @config's Sort and of its
+// Indexes, in any order.
+func paginatorFingerprint(config SearchConfig) []byte {
+	indexes := append([]string(nil), config.Indexes...)
+	sort.Strings(indexes)
+
+	h := sha256.New()
+	for _, index := range indexes {
+		h.Write([]byte(index))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{0xff})
+	for _, sorter := range config.Sort.Strings() {
+		h.Write([]byte(sorter))
+		h.Write([]byte{0})
+	}
+	return h.Sum(nil)[:paginatorFingerprintSize]
+}
+
+// decodePaginator replaces the @config's SearchAfter and SearchBefore by
+// the sort values of the paginators in them, when the client has a
+// PaginatorCodec.
+func (c *esClient) decodePaginator(config SearchConfig) (SearchConfig, error) {
+	if c.paginatorCodec == nil {
+		return config, nil
+	}
+
+	for _, cursor := range []*string{&config.SearchAfter, &config.SearchBefore} {
+		if *cursor == "" {
+			continue
+		}
+		sortValues, err := c.paginatorCodec.Decode(config, *cursor)
+		if err != nil {
+			return SearchConfig{}, errors.E(err, ErrCodeBadRequest)
+		}
+		*cursor = sortValues
+	}
+	return config, nil
+}
+
+// encodePaginator replaces the @response's Paginator and PreviousPaginator
+// by their encoded forms, when the client has a PaginatorCodec.
+func (c *esClient) encodePaginator(config SearchConfig, response SearchResponse) (SearchResponse, error) {
+	if c.paginatorCodec == nil {
+		return response, nil
+	}
+
+	for _, paginator := range []*string{&response.Paginator, &response.PreviousPaginator} {
+		if *paginator == "" {
+			continue
+		}
+		encoded, err := c.paginatorCodec.Encode(config, *paginator)
+		if err != nil {
+			return SearchResponse{}, errors.E(err, ErrCodeUnexpectedResponse)
+		}
+		*paginator = encoded
+	}
+	return response, nil
+}
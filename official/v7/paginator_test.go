@@ -0,0 +1,180 @@
+package v7
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PaginatorCodec(t *testing.T) {
+	t.Parallel()
+
+	codec, err := NewPaginatorCodec([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	config := SearchConfig{
+		Indexes: []string{"index1", "index2"},
+		Sort:    Sorters{Sorters: []Sorter{{Field: "Date", Ascending: true}, {Field: "ID", Ascending: false}}},
+	}
+	paginator, err := codec.Encode(config, `[1700000000000,"id-1"]`)
+	require.NoError(t, err)
+	assert.NotContains(t, paginator, "id-1")
+
+	tampered := func() string {
+		token, err := base64.RawURLEncoding.DecodeString(paginator)
+		require.NoError(t, err)
+		token[paginatorFingerprintSize+1] = '2'
+		return base64.RawURLEncoding.EncodeToString(token)
+	}()
+	otherCodec, err := NewPaginatorCodec([]byte("another key"))
+	require.NoError(t, err)
+	otherKeyPaginator, err := otherCodec.Encode(config, `[1700000000000,"id-1"]`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                string
+		config              SearchConfig
+		paginator           string
+		expectedSearchAfter string
+		expectedError       error
+	}{
+		{
+			name:                "same search",
+			config:              config,
+			paginator:           paginator,
+			expectedSearchAfter: `[1700000000000,"id-1"]`,
+		},
+		{
+			name: "indexes in another order",
+			config: SearchConfig{
+				Indexes: []string{"index2", "index1"},
+				Sort:    config.Sort,
+			},
+			paginator:           paginator,
+			expectedSearchAfter: `[1700000000000,"id-1"]`,
+		},
+		{
+			name: "another sort",
+			config: SearchConfig{
+				Indexes: config.Indexes,
+				Sort:    Sorters{Sorters: []Sorter{{Field: "Date", Ascending: false}, {Field: "ID", Ascending: false}}},
+			},
+			paginator:     paginator,
+			expectedError: ErrPaginatorMismatch,
+		},
+		{
+			name: "another indexes",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Sort:    config.Sort,
+			},
+			paginator:     paginator,
+			expectedError: ErrPaginatorMismatch,
+		},
+		{
+			name:          "tampered",
+			config:        config,
+			paginator:     tampered,
+			expectedError: ErrInvalidPaginator,
+		},
+		{
+			name:          "another key",
+			config:        config,
+			paginator:     otherKeyPaginator,
+			expectedError: ErrInvalidPaginator,
+		},
+		{
+			name:          "raw sort values",
+			config:        config,
+			paginator:     `[1700000000000,"id-1"]`,
+			expectedError: ErrInvalidPaginator,
+		},
+		{
+			name:          "too short",
+			config:        config,
+			paginator:     "c2hvcnQ",
+			expectedError: ErrInvalidPaginator,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			searchAfter, err := codec.Decode(test.config, test.paginator)
+			if test.expectedError != nil {
+				assert.True(t, errors.Is(err, test.expectedError), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedSearchAfter, searchAfter)
+		})
+	}
+}
+
+func Test_PaginatorCodec_Encode_invalidSearchAfter(t *testing.T) {
+	t.Parallel()
+
+	codec, err := NewPaginatorCodec([]byte("key"))
+	require.NoError(t, err)
+
+	_, err = codec.Encode(SearchConfig{}, `{"paginator"}`)
+	assert.True(t, errors.Is(err, ErrInvalidSearchAfter), "unexpected error: %v", err)
+}
+
+func Test_NewPaginatorCodec_emptyKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewPaginatorCodec(nil)
+	assert.EqualError(t, err, "v7.NewPaginatorCodec: [key] is required")
+}
+
+func Test_Search_withPaginatorCodec(t *testing.T) {
+	t.Parallel()
+
+	codec, err := NewPaginatorCodec([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	config := SearchConfig{
+		Indexes: []string{"index1"},
+		Size:    2,
+		Sort:    Sorters{Sorters: []Sorter{{Field: "ID", Ascending: true}}},
+	}
+	searchAfter, err := codec.Encode(config, `["id-2"]`)
+	require.NoError(t, err)
+	config.SearchAfter = searchAfter
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=2&sort=ID%3Aasc&track_total_hits=false",
+		`{"query":{"match_all":{}},"search_after":["id-2"]}`,
+	).Once().Return(
+		`{"took":1,"_shards":{"total":1,"successful":1},"hits":{"hits":[{"_index":"index1","_id":"id-3","sort":["id-3"]},{"_index":"index1","_id":"id-4","sort":["id-4"]}]}}`,
+		200,
+		nil,
+	)
+	defer transport.AssertExpectations(t)
+
+	client := mustNewClientTest(transport).(*esClient)
+	client.paginatorCodec = codec
+
+	response, err := client.Search(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id-3", "id-4"}, response.IDs)
+
+	next, err := codec.Decode(config, response.Paginator)
+	require.NoError(t, err)
+	assert.Equal(t, `["id-4"]`, next)
+
+	config.SearchAfter = `["id-4"]`
+	_, err = client.Search(context.Background(), config)
+	assert.True(t, errors.Is(err, ErrInvalidPaginator), "unexpected error: %v", err)
+	assert.Equal(t, ErrCodeBadRequest, errors.GetCode(err))
+	transport.AssertNumberOfCalls(t, "RoundTrip", 1)
+}
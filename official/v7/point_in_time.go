@@ -0,0 +1,143 @@
+package v7
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// OpenPointInTimeConfig hold all information to OpenPointInTime method.
+type OpenPointInTimeConfig struct {
+	Indexes           []string
+	KeepAlive         time.Duration
+	IgnoreUnavailable bool
+}
+
+// PointInTime represents a point in time used by the Search method. When it
+// is set, the search runs against the indexes the point in time was opened
+// with, and SearchConfig's Indexes, IgnoreUnavailable and AllowNoIndices are
+// ignored.
+type PointInTime struct {
+	ID string
+	// KeepAlive extends the point in time's life on every search.
+	KeepAlive time.Duration
+}
+
+type envelopeOpenPointInTime struct {
+	ID string `json:"id"`
+}
+
+func (p PointInTime) source() map[string]interface{} {
+	source := map[string]interface{}{
+		"id": p.ID,
+	}
+	if p.KeepAlive > 0 {
+		source["keep_alive"] = formatKeepAlive(p.KeepAlive)
+	}
+	return source
+}
+
+// OpenPointInTime opens a point in time on the @config's Indexes and returns
+// its ID.
+func (c *esClient) OpenPointInTime(ctx context.Context, config OpenPointInTimeConfig) (string, error) {
+	const op = errors.Op("v7.Client.OpenPointInTime")
+
+	enrichLogWithIndexes(ctx, config.Indexes)
+
+	response, err := c.doOpenPointInTime(ctx, config)
+	if err != nil {
+		return "", errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	var r envelopeOpenPointInTime
+	err = json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return "", errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return r.ID, nil
+}
+
+func (c *esClient) doOpenPointInTime(
+	ctx context.Context,
+	config OpenPointInTimeConfig,
+) (*esapi.Response, error) {
+	const op = errors.Op("doOpenPointInTime")
+
+	if len(config.Indexes) == 0 {
+		return nil, errors.E(op, requiredFieldError("Indexes"), ErrCodeBadRequest)
+	}
+	if config.KeepAlive <= 0 {
+		return nil, errors.E(op, requiredFieldError("KeepAlive"), ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.OpenPointInTimeRequest){
+		c.client.OpenPointInTime.WithContext(ctx),
+	}
+	if config.IgnoreUnavailable {
+		options = append(options, c.client.OpenPointInTime.WithIgnoreUnavailable(true))
+	}
+
+	response, err := c.client.OpenPointInTime(
+		config.Indexes,
+		formatKeepAlive(config.KeepAlive),
+		options...,
+	)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+// ClosePointInTime releases the point in time identified by @id.
+func (c *esClient) ClosePointInTime(ctx context.Context, id string) error {
+	const op = errors.Op("v7.Client.ClosePointInTime")
+
+	if id == "" {
+		return errors.E(op, requiredFieldError("id"), ErrCodeBadRequest)
+	}
+
+	body, err := encodeBody(map[string]string{"id": id})
+	if err != nil {
+		return errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	response, err := c.client.ClosePointInTime(
+		c.client.ClosePointInTime.WithContext(ctx),
+		c.client.ClosePointInTime.WithBody(body),
+	)
+	if err != nil {
+		return errors.E(op, err, ErrCodeBadGateway)
+	}
+	defer response.Body.Close()
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return errors.E(op, err)
+	}
+
+	return nil
+}
+
+// formatKeepAlive formats @d as an elasticsearch time unit.
+func formatKeepAlive(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
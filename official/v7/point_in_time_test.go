@@ -0,0 +1,149 @@
+package v7
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SearchWithPointInTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		config            SearchConfig
+		transport         *mockTransport
+		expectedIDs       [][]string
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    2,
+				Filter:  Filter{},
+				Sort: Sorters{
+					Sorters: []Sorter{{Field: "Name", Ascending: true}},
+				},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_pit?keep_alive=60000ms",
+					"",
+				).Once().Return(`{"id":"pit-1"}`, 200, nil)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search?size=2&sort=Name%3Aasc%2C_shard_doc%3Aasc&track_total_hits=false",
+					`{"query":{"match_all":{}},"pit":{"id":"pit-1","keep_alive":"60000ms"}}`,
+				).Once().Return(
+					`{"pit_id":"pit-2","took":1,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"hits":[{"_id":"id-1","sort":["A",1]},{"_id":"id-2","sort":["B",2]}]}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search?size=2&sort=Name%3Aasc%2C_shard_doc%3Aasc&track_total_hits=false",
+					`{"query":{"match_all":{}},"search_after":["B",2],"pit":{"id":"pit-2","keep_alive":"60000ms"}}`,
+				).Once().Return(
+					`{"pit_id":"pit-2","took":1,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"hits":[{"_id":"id-3","sort":["C",3]}]}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_pit",
+					`{"id":"pit-2"}`,
+				).Once().Return(`{"succeeded":true,"num_freed":1}`, 200, nil)
+				return server
+			}(),
+			expectedIDs: [][]string{{"id-1", "id-2"}, {"id-3"}},
+		},
+		{
+			name: "search error closes the point in time",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    2,
+				Filter:  Filter{},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_pit?keep_alive=60000ms",
+					"",
+				).Once().Return(`{"id":"pit-1"}`, 200, nil)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search?size=2&sort=_shard_doc%3Aasc&track_total_hits=false",
+					`{"query":{"match_all":{}},"pit":{"id":"pit-1","keep_alive":"60000ms"}}`,
+				).Once().Return(
+					`{"error":{"root_cause":[{"type":"search_context_missing_exception","reason":"No search context found"}],"type":"search_phase_execution_exception","reason":"all shards failed"},"status":404}`,
+					404,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_pit",
+					`{"id":"pit-1"}`,
+				).Once().Return(`{"succeeded":true,"num_freed":1}`, 200, nil)
+				return server
+			}(),
+			expectedError:     "v7.SearchWithPointInTime: v7.Client.Search: doSearch: [404 Not Found] search_phase_execution_exception: all shards failed: No search context found",
+			expectedErrorCode: ErrCodeNotFound,
+		},
+		{
+			name: "missing size",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+			},
+			transport:         new(mockTransport),
+			expectedError:     "v7.SearchWithPointInTime: [Size] is required",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+		{
+			name: "search before",
+			config: SearchConfig{
+				Indexes:      []string{"index1"},
+				Size:         2,
+				Sort:         Sorters{Sorters: []Sorter{{Field: "ID", Ascending: true}}},
+				SearchBefore: `["id-3"]`,
+			},
+			transport:         new(mockTransport),
+			expectedError:     "v7.SearchWithPointInTime: point in time iteration does not support search_before",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+
+			var (
+				ids [][]string
+				err error
+			)
+			for response, e := range SearchWithPointInTime(context.Background(), client, test.config, time.Minute) {
+				if e != nil {
+					err = e
+					break
+				}
+				ids = append(ids, response.IDs)
+			}
+
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedIDs, ids)
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package querybuilders
+
+// Highlight allows highlighting search results on one or more fields.
+//
+// For more details, see:
+// https://www.elastic.co/guide/en/elasticsearch/reference/7.0/search-request-highlighting.html
+type Highlight struct {
+	fields            []*HighlighterField
+	preTags           []string
+	postTags          []string
+	fragmentSize      *int
+	numOfFragments    *int
+	highlighterType   string
+	order             string
+	encoder           string
+	requireFieldMatch *bool
+	highlightQuery    Query
+}
+
+// NewHighlight creates and initializes a new Highlight.
+func NewHighlight() *Highlight {
+	return &Highlight{}
+}
+
+// Fields adds the @fields to be highlighted.
+func (hl *Highlight) Fields(fields ...*HighlighterField) *Highlight {
+	hl.fields = append(hl.fields, fields...)
+	return hl
+}
+
+// Field adds a field to be highlighted with the default settings.
+func (hl *Highlight) Field(name string) *Highlight {
+	return hl.Fields(NewHighlighterField(name))
+}
+
+// PreTags sets the tags inserted before each highlighted term.
+// The default is "<em>".
+func (hl *Highlight) PreTags(preTags ...string) *Highlight {
+	hl.preTags = preTags
+	return hl
+}
+
+// PostTags sets the tags inserted after each highlighted term.
+// The default is "</em>".
+func (hl *Highlight) PostTags(postTags ...string) *Highlight {
+	hl.postTags = postTags
+	return hl
+}
+
+// FragmentSize sets the size of the highlighted fragments in characters.
+func (hl *Highlight) FragmentSize(fragmentSize int) *Highlight {
+	hl.fragmentSize = &fragmentSize
+	return hl
+}
+
+// NumOfFragments sets the maximum number of fragments returned per field.
+// If it is 0, the whole field is returned highlighted.
+func (hl *Highlight) NumOfFragments(numOfFragments int) *Highlight {
+	hl.numOfFragments = &numOfFragments
+	return hl
+}
+
+// HighlighterType sets the highlighter to be used: "unified", "plain" or
+// "fvh".
+func (hl *Highlight) HighlighterType(highlighterType string) *Highlight {
+	hl.highlighterType = highlighterType
+	return hl
+}
+
+// Order sets how the fragments are sorted. Use "score" to sort them by
+// relevance.
+func (hl *Highlight) Order(order string) *Highlight {
+	hl.order = order
+	return hl
+}
+
+// Encoder sets how the highlighted text is encoded: "default" or "html".
+func (hl *Highlight) Encoder(encoder string) *Highlight {
+	hl.encoder = encoder
+	return hl
+}
+
+// RequireFieldMatch sets whether only the fields that matched the query are
+// highlighted.
+func (hl *Highlight) RequireFieldMatch(requireFieldMatch bool) *Highlight {
+	hl.requireFieldMatch = &requireFieldMatch
+	return hl
+}
+
+// HighlightQuery sets a query to highlight other than the search query.
+func (hl *Highlight) HighlightQuery(query Query) *Highlight {
+	hl.highlightQuery = query
+	return hl
+}
+
+// Source returns the JSON serializable content for this highlight.
+func (hl *Highlight) Source() (interface{}, error) {
+	// {
+	//   "pre_tags" : ["<em>"],
+	//   "post_tags" : ["</em>"],
+	//   "fields" : {
+	//     "content" : { "fragment_size" : 150, "number_of_fragments" : 3 }
+	//   }
+	// }
+
+	source := make(map[string]interface{})
+	if len(hl.preTags) > 0 {
+		source["pre_tags"] = hl.preTags
+	}
+	if len(hl.postTags) > 0 {
+		source["post_tags"] = hl.postTags
+	}
+	if hl.fragmentSize != nil {
+		source["fragment_size"] = *hl.fragmentSize
+	}
+	if hl.numOfFragments != nil {
+		source["number_of_fragments"] = *hl.numOfFragments
+	}
+	if hl.highlighterType != "" {
+		source["type"] = hl.highlighterType
+	}
+	if hl.order != "" {
+		source["order"] = hl.order
+	}
+	if hl.encoder != "" {
+		source["encoder"] = hl.encoder
+	}
+	if hl.requireFieldMatch != nil {
+		source["require_field_match"] = *hl.requireFieldMatch
+	}
+	if hl.highlightQuery != nil {
+		query, err := hl.highlightQuery.Source()
+		if err != nil {
+			return nil, err
+		}
+		source["highlight_query"] = query
+	}
+
+	fields := make(map[string]interface{}, len(hl.fields))
+	for _, field := range hl.fields {
+		fieldSource, err := field.Source()
+		if err != nil {
+			return nil, err
+		}
+		fields[field.name] = fieldSource
+	}
+	source["fields"] = fields
+
+	return source, nil
+}
+
+// HighlighterField represents a field to be highlighted. Its settings
+// override the ones of the Highlight.
+type HighlighterField struct {
+	name            string
+	preTags         []string
+	postTags        []string
+	fragmentSize    *int
+	numOfFragments  *int
+	highlighterType string
+	matchedFields   []string
+}
+
+// NewHighlighterField creates and initializes a new HighlighterField.
+func NewHighlighterField(name string) *HighlighterField {
+	return &HighlighterField{
+		name: name,
+	}
+}
+
+// PreTags sets the tags inserted before each highlighted term of this field.
+func (f *HighlighterField) PreTags(preTags ...string) *HighlighterField {
+	f.preTags = preTags
+	return f
+}
+
+// PostTags sets the tags inserted after each highlighted term of this field.
+func (f *HighlighterField) PostTags(postTags ...string) *HighlighterField {
+	f.postTags = postTags
+	return f
+}
+
+// FragmentSize sets the size of the highlighted fragments of this field in
+// characters.
+func (f *HighlighterField) FragmentSize(fragmentSize int) *HighlighterField {
+	f.fragmentSize = &fragmentSize
+	return f
+}
+
+// NumOfFragments sets the maximum number of fragments returned for this
+// field.
+func (f *HighlighterField) NumOfFragments(numOfFragments int) *HighlighterField {
+	f.numOfFragments = &numOfFragments
+	return f
+}
+
+// HighlighterType sets the highlighter used for this field.
+func (f *HighlighterField) HighlighterType(highlighterType string) *HighlighterField {
+	f.highlighterType = highlighterType
+	return f
+}
+
+// MatchedFields sets the fields whose matches are combined to highlight
+// this field. It is only supported by the "fvh" highlighter.
+func (f *HighlighterField) MatchedFields(matchedFields ...string) *HighlighterField {
+	f.matchedFields = matchedFields
+	return f
+}
+
+// Source returns the JSON serializable content for this field.
+func (f *HighlighterField) Source() (interface{}, error) {
+	source := make(map[string]interface{})
+	if len(f.preTags) > 0 {
+		source["pre_tags"] = f.preTags
+	}
+	if len(f.postTags) > 0 {
+		source["post_tags"] = f.postTags
+	}
+	if f.fragmentSize != nil {
+		source["fragment_size"] = *f.fragmentSize
+	}
+	if f.numOfFragments != nil {
+		source["number_of_fragments"] = *f.numOfFragments
+	}
+	if f.highlighterType != "" {
+		source["type"] = f.highlighterType
+	}
+	if len(f.matchedFields) > 0 {
+		source["matched_fields"] = f.matchedFields
+	}
+	return source, nil
+}
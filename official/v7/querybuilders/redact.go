@@ -0,0 +1,85 @@
+package querybuilders
+
+// RedactedValue replaces the values removed from a query by Redact.
+const RedactedValue = "?"
+
+// Redactor is implemented by the queries that can hide the values they
+// search for, such as the term, terms and multi match queries, or that hold
+// other queries, such as the bool and nested queries.
+type Redactor interface {
+	// Redacted returns a copy of the query with its values replaced by
+	// RedactedValue and its fields and structure preserved.
+	Redacted() Query
+}
+
+// Redact returns a copy of @query with the values it searches for replaced
+// by RedactedValue, so it can be logged without exposing them. Queries that
+// do not implement Redactor are returned as they are.
+func Redact(query Query) Query {
+	if redactor, ok := query.(Redactor); ok {
+		return redactor.Redacted()
+	}
+	return query
+}
+
+func redactAll(queries []Query) []Query {
+	redacted := make([]Query, 0, len(queries))
+	for _, query := range queries {
+		redacted = append(redacted, Redact(query))
+	}
+	return redacted
+}
+
+// Redacted returns a copy of the query with its value replaced by
+// RedactedValue.
+func (q *TermQuery) Redacted() Query {
+	redacted := *q
+	redacted.value = RedactedValue
+	return &redacted
+}
+
+// Redacted returns a copy of the query with each of its values replaced by
+// RedactedValue. A terms lookup is kept as it is.
+func (q *TermsQuery) Redacted() Query {
+	redacted := *q
+	redacted.values = make([]interface{}, 0, len(q.values))
+	for range q.values {
+		redacted.values = append(redacted.values, RedactedValue)
+	}
+	return &redacted
+}
+
+// Redacted returns a copy of the query with its text replaced by
+// RedactedValue.
+func (q *MultiMatchQuery) Redacted() Query {
+	redacted := *q
+	redacted.text = RedactedValue
+	return &redacted
+}
+
+// Redacted returns a copy of the query with all of its clauses redacted.
+func (q *BoolQuery) Redacted() Query {
+	redacted := *q
+	redacted.mustClauses = redactAll(q.mustClauses)
+	redacted.mustNotClauses = redactAll(q.mustNotClauses)
+	redacted.filterClauses = redactAll(q.filterClauses)
+	redacted.shouldClauses = redactAll(q.shouldClauses)
+	return &redacted
+}
+
+// Redacted returns a copy of the query with its inner query redacted.
+func (q *NestedQuery) Redacted() Query {
+	redacted := *q
+	redacted.query = Redact(q.query)
+	return &redacted
+}
+
+// Redacted returns a copy of the highlight with its highlight query
+// redacted.
+func (hl *Highlight) Redacted() *Highlight {
+	redacted := *hl
+	if hl.highlightQuery != nil {
+		redacted.highlightQuery = Redact(hl.highlightQuery)
+	}
+	return &redacted
+}
@@ -0,0 +1,52 @@
+package querytest
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// update makes Golden write the golden files instead of comparing them:
+//
+//	go test ./... -args -querytest.update
+var update = flag.Bool("querytest.update", false, "update the querytest golden files")
+
+// Golden asserts that the @actual query or body is semantically equal to
+// the JSON in the golden file named @filename, usually under testdata. When
+// the tests run with the -querytest.update flag, the golden file is written
+// with the normalized and indented @actual value instead.
+func Golden(t assert.TestingT, filename string, actual interface{}, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	if *update {
+		return assert.NoError(t, writeGolden(filename, actual), msgAndArgs...)
+	}
+
+	expected, err := os.ReadFile(filename)
+	if err != nil {
+		return assert.Fail(t, "Cannot read the golden file: "+err.Error(), msgAndArgs...)
+	}
+	return Equal(t, expected, actual, msgAndArgs...)
+}
+
+func writeGolden(filename string, actual interface{}) error {
+	normalized, err := Normalize(actual)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(normalized, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(content, '\n'), 0o600)
+}
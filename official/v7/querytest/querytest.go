@@ -0,0 +1,255 @@
+// Package querytest provides assertions that compare elasticsearch queries
+// and request bodies as JSON values, so tests do not depend on the order of
+// the keys or on the formatting of the bodies built by the v7.Client.
+package querytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+var errTrailingData = errors.New("invalid data after the JSON value")
+
+// Normalize returns the @value as decoded JSON. The @value may be a
+// querybuilders.Query, a JSON or NDJSON body as a string, []byte or
+// json.RawMessage, or any value that can be encoded as JSON. The lines of an
+// NDJSON body are returned as an array and an empty body as nil. Integral
+// numbers are returned as int64 and the other numbers as float64, so 1 and
+// 1.0 are the same.
+func Normalize(value interface{}) (interface{}, error) {
+	var body []byte
+	switch v := value.(type) {
+	case querybuilders.Query:
+		source, err := v.Source()
+		if err != nil {
+			return nil, err
+		}
+		body, err = json.Marshal(source)
+		if err != nil {
+			return nil, err
+		}
+	case string:
+		body = []byte(v)
+	case json.RawMessage:
+		body = v
+	case []byte:
+		body = v
+	default:
+		var err error
+		body, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return decodeBody(body)
+}
+
+func decodeBody(body []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	value, err := decode(body)
+	if err == nil {
+		return value, nil
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(body), []byte("\n"))
+	if len(lines) < 2 {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(lines))
+	for _, line := range lines {
+		value, err := decode(line)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.E(errTrailingData, errors.KV("offset", decoder.InputOffset()))
+	}
+	return normalizeNumbers(value)
+}
+
+func normalizeNumbers(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		if f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int64(f), nil
+		}
+		return f, nil
+	case map[string]interface{}:
+		for key, child := range v {
+			normalized, err := normalizeNumbers(child)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = normalized
+		}
+	case []interface{}:
+		for i, child := range v {
+			normalized, err := normalizeNumbers(child)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+	}
+	return value, nil
+}
+
+// Diff returns the differences between the @expected and the @actual
+// values, normalized with Normalize, one per line, each one with the path
+// of the difference, such as:
+//
+//	$.query.bool.must[0].terms.Name[1]: expected "Mary", got "Paul"
+//	$.size: missing, expected 10
+//	$.from: unexpected 0
+//
+// There are no differences when the values are semantically equal.
+func Diff(expected, actual interface{}) ([]string, error) {
+	const op = errors.Op("querytest.Diff")
+
+	normalizedExpected, err := Normalize(expected)
+	if err != nil {
+		return nil, errors.E(op, err, errors.KV("value", "expected"))
+	}
+	normalizedActual, err := Normalize(actual)
+	if err != nil {
+		return nil, errors.E(op, err, errors.KV("value", "actual"))
+	}
+
+	return diff("$", normalizedExpected, normalizedActual, nil), nil
+}
+
+// nolint: cyclop
+func diff(path string, expected, actual interface{}, diffs []string) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, isObject := actual.(map[string]interface{})
+		if !isObject {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		for key := range a {
+			if _, ok := e[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := path + "." + key
+			expectedChild, inExpected := e[key]
+			actualChild, inActual := a[key]
+			switch {
+			case !inActual:
+				diffs = append(diffs, childPath+": missing, expected "+format(expectedChild))
+			case !inExpected:
+				diffs = append(diffs, childPath+": unexpected "+format(actualChild))
+			default:
+				diffs = diff(childPath, expectedChild, actualChild, diffs)
+			}
+		}
+		return diffs
+	case []interface{}:
+		a, isArray := actual.([]interface{})
+		if !isArray {
+			break
+		}
+		for i := 0; i < max(len(e), len(a)); i++ {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(a):
+				diffs = append(diffs, childPath+": missing, expected "+format(e[i]))
+			case i >= len(e):
+				diffs = append(diffs, childPath+": unexpected "+format(a[i]))
+			default:
+				diffs = diff(childPath, e[i], a[i], diffs)
+			}
+		}
+		return diffs
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		diffs = append(diffs, path+": expected "+format(expected)+", got "+format(actual))
+	}
+	return diffs
+}
+
+func format(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// Equal asserts that the @expected and the @actual values are semantically
+// equal JSON values, as compared by Diff. The @expected and @actual values
+// may be queries or bodies, in any of the forms accepted by Normalize. On
+// failure, the differences are reported with their paths.
+func Equal(t assert.TestingT, expected, actual interface{}, msgAndArgs ...interface{}) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+
+	diffs, err := Diff(expected, actual)
+	if err != nil {
+		return assert.Fail(t, "Cannot compare the JSON values: "+err.Error(), msgAndArgs...)
+	}
+	if len(diffs) > 0 {
+		return assert.Fail(t, "JSON values are not equal:\n"+strings.Join(diffs, "\n"), msgAndArgs...)
+	}
+	return true
+}
+
+// Match returns a testify mock matcher for string or []byte arguments, such
+// as a request body, that are semantically equal to the @expected value.
+func Match(expected interface{}) interface{} {
+	return mock.MatchedBy(func(actual interface{}) bool {
+		switch actual.(type) {
+		case string, []byte, json.RawMessage:
+		default:
+			return false
+		}
+		diffs, err := Diff(expected, actual)
+		return err == nil && len(diffs) == 0
+	})
+}
@@ -0,0 +1,147 @@
+package querytest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_Diff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		expected      interface{}
+		actual        interface{}
+		expectedDiffs []string
+		expectedError string
+	}{
+		{
+			name: "query and body with other key order and formatting",
+			expected: querybuilders.NewBoolQuery().
+				Must(querybuilders.NewTermsQuery("Name", "John", "Mary")).
+				MustNot(querybuilders.NewTermQuery("Age", 30)),
+			actual: "{\"bool\": {\"must_not\": {\"term\": {\"Age\": 30.0}},\n" +
+				"\t\"must\": {\"terms\": {\"Name\": [\"John\", \"Mary\"]}}}}",
+		},
+		{
+			name:     "changed, missing and unexpected values",
+			expected: `{"query":{"terms":{"Name":["John","Mary"]}},"size":10}`,
+			actual:   `{"query":{"terms":{"Name":["John","Paul","Rebecca"]}},"from":0}`,
+			expectedDiffs: []string{
+				`$.from: unexpected 0`,
+				`$.query.terms.Name[1]: expected "Mary", got "Paul"`,
+				`$.query.terms.Name[2]: unexpected "Rebecca"`,
+				`$.size: missing, expected 10`,
+			},
+		},
+		{
+			name:     "different types",
+			expected: `{"size":10}`,
+			actual:   `{"size":"10"}`,
+			expectedDiffs: []string{
+				`$.size: expected 10, got "10"`,
+			},
+		},
+		{
+			name:     "ndjson",
+			expected: "{\"index\":[\"index1\"]}\n{\"size\":10}\n",
+			actual:   []byte("{\"index\": [\"index1\"]}\n{\"size\": 20}\n"),
+			expectedDiffs: []string{
+				`$[1].size: expected 10, got 20`,
+			},
+		},
+		{
+			name:     "values encoded as JSON",
+			expected: map[string]interface{}{"size": 10},
+			actual:   struct{ Size int }{Size: 10},
+			expectedDiffs: []string{
+				`$.Size: unexpected 10`,
+				`$.size: missing, expected 10`,
+			},
+		},
+		{
+			name:          "invalid body",
+			expected:      `{"search_after": {"paginator"}}`,
+			actual:        `{}`,
+			expectedError: "querytest.Diff",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			diffs, err := Diff(test.expected, test.actual)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDiffs, diffs)
+		})
+	}
+}
+
+func Test_Equal(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Equal(t, `{"size":10, "from":0}`, `{"from":0,"size":10.0}`))
+
+	recorder := &recordingT{}
+	assert.False(t, Equal(recorder, `{"size":10}`, `{"size":20}`, "search %s", "body"))
+	require.Len(t, recorder.errors, 1)
+	assert.Contains(t, recorder.errors[0], "JSON values are not equal:")
+	assert.Contains(t, recorder.errors[0], "$.size: expected 10, got 20")
+	assert.Contains(t, recorder.errors[0], "search body")
+}
+
+func Test_Golden(t *testing.T) {
+	t.Parallel()
+
+	query := querybuilders.NewNestedQuery(
+		"Covid",
+		querybuilders.NewBoolQuery().Must(
+			querybuilders.NewTermsQuery("Covid.Symptom", "cough"),
+			querybuilders.NewExistsQuery("Covid.Date"),
+		),
+	)
+	Golden(t, filepath.Join("testdata", "nested_query.json"), query)
+	if *update {
+		return
+	}
+
+	recorder := &recordingT{}
+	assert.False(t, Golden(recorder, filepath.Join("testdata", "nested_query.json"), querybuilders.NewMatchAllQuery()))
+	assert.False(t, Golden(recorder, filepath.Join("testdata", "missing.json"), query))
+	assert.Len(t, recorder.errors, 2)
+}
+
+func Test_Match(t *testing.T) {
+	t.Parallel()
+
+	m := &mock.Mock{}
+	m.On("RoundTrip", Match(`{"query":{"match_all":{}},"size":10}`)).Return(nil)
+
+	assert.NotPanics(t, func() {
+		m.MethodCalled("RoundTrip", "{\"size\": 10, \t\"query\": {\"match_all\": {}}}")
+	})
+	assert.Panics(t, func() {
+		m.MethodCalled("RoundTrip", `{"query":{"match_all":{}},"size":20}`)
+	})
+}
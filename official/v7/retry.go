@@ -0,0 +1,130 @@
+package v7
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// RetryPolicy configures how the Client retries a failed operation.
+//
+// The operation is tried up to MaxAttempts times. Between attempts, the
+// Client waits InitialBackoff, multiplied by Multiplier on every retry and
+// limited to MaxBackoff. Jitter is the fraction, from 0 to 1, of each wait
+// that is randomized. Retries stop when MaxElapsedTime has elapsed since the
+// first attempt or when the next wait would exceed the context's deadline.
+//
+// Elasticsearch errors are retried when their status is in RetryOnStatus,
+// responses where not all shards replied are retried when
+// RetryOnPartialShards is set and network errors are always retried.
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	Multiplier           float64
+	Jitter               float64
+	RetryOnStatus        []int
+	RetryOnPartialShards bool
+	MaxElapsedTime       time.Duration
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by the Client when none
+// is given.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryOnStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		MaxElapsedTime: 10 * time.Second,
+	}
+}
+
+// IsRetryable returns true if @err is worth retrying according to the
+// DefaultRetryPolicy, that is, if it is a network error or an elasticsearch
+// error with status 429, 502, 503 or 504.
+func IsRetryable(err error) bool {
+	return DefaultRetryPolicy().IsRetryable(err)
+}
+
+// IsRetryable returns true if @err is worth retrying according to the
+// policy. Errors caused by the context being done are never retryable.
+func (p RetryPolicy) IsRetryable(err error) bool {
+	if err == nil ||
+		stderrors.Is(err, context.Canceled) ||
+		stderrors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var elasticError *ElasticError
+	if stderrors.As(err, &elasticError) {
+		return slices.Contains(p.RetryOnStatus, elasticError.Status)
+	}
+
+	if stderrors.Is(err, ErrNotAllShardsReplied) {
+		return p.RetryOnPartialShards
+	}
+
+	var netError net.Error
+	if stderrors.As(err, &netError) {
+		return true
+	}
+
+	return stderrors.Is(err, io.ErrUnexpectedEOF) || stderrors.Is(err, io.EOF)
+}
+
+// backoff returns how long to wait before the retry @attempt, starting at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < attempt && p.Multiplier > 0; i++ {
+		backoff *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff -= backoff * min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(backoff)
+}
+
+// do calls @fn until it succeeds, returns an error that is not retryable
+// or the policy gives up. It returns the last error returned by @fn.
+func (p RetryPolicy) do(ctx context.Context, fn func() error) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !p.IsRetryable(err) {
+			return err
+		}
+
+		backoff := p.backoff(attempt)
+		if p.MaxElapsedTime > 0 && time.Since(start)+backoff > p.MaxElapsedTime {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		recordRetry(ctx)
+	}
+}
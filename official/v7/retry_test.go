@@ -0,0 +1,269 @@
+package v7
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IsRetryable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "too many requests",
+			err:      errors.E(errors.Op("op"), &ElasticError{Status: 429}, ErrCodeTooManyRequests),
+			expected: true,
+		},
+		{
+			name:     "service unavailable",
+			err:      &ElasticError{Status: 503},
+			expected: true,
+		},
+		{
+			name:     "bad request",
+			err:      errors.E(&ElasticError{Status: 400}, ErrCodeBadRequest),
+			expected: false,
+		},
+		{
+			name:     "network error",
+			err:      errors.E(errors.Op("op"), &net.OpError{Op: "dial", Err: errors.New("connection refused")}),
+			expected: true,
+		},
+		{
+			name:     "not all shards replied",
+			err:      errors.E(ErrNotAllShardsReplied, ErrCodeBadGateway),
+			expected: false,
+		},
+		{
+			name:     "context canceled",
+			err:      errors.E(errors.Op("op"), context.Canceled),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, IsRetryable(test.err))
+		})
+	}
+}
+
+func Test_RetryPolicy_backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, policy.backoff(4))
+	assert.Equal(t, time.Second, policy.backoff(5))
+
+	policy.Jitter = 0.5
+	for attempt := 1; attempt < 10; attempt++ {
+		backoff := policy.backoff(attempt)
+		assert.LessOrEqual(t, backoff, time.Second)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+	}
+}
+
+func Test_Search_Retry(t *testing.T) {
+	t.Parallel()
+
+	tooManyRequests := `{"error":{"root_cause":[{"type":"es_rejected_execution_exception","reason":"rejected execution"}],"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":429}`
+
+	tests := []struct {
+		name              string
+		transport         func() *mockTransport
+		expectedResponse  SearchResponse
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success after retry",
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On("RoundTrip", "http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false", `{"query":{"match_all":{}}}`).
+					Once().Return(tooManyRequests, 429, nil)
+				server.On("RoundTrip", "http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false", `{"query":{"match_all":{}}}`).
+					Once().Return(`{"took":1,"hits":{"total":{"value":1},"hits":[{"_index":"index1","_id":"id-1"}]}}`, 200, nil)
+				return server
+			},
+			expectedResponse: SearchResponse{
+				IDs:   []string{"id-1"},
+				Hits:  []SearchHit{{ID: "id-1", Index: "index1"}},
+				Total: 1,
+				Took:  1,
+			},
+		},
+		{
+			name: "gives up after max attempts",
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On("RoundTrip", "http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false", `{"query":{"match_all":{}}}`).
+					Times(2).Return(tooManyRequests, 429, nil)
+				return server
+			},
+			expectedError:     "v7.Client.Search: doSearch: [429 Too Many Requests] es_rejected_execution_exception: rejected execution: rejected execution",
+			expectedErrorCode: ErrCodeTooManyRequests,
+		},
+		{
+			name: "does not retry bad request",
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On("RoundTrip", "http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false", `{"query":{"match_all":{}}}`).
+					Once().Return(`{"error":{"root_cause":[{"type":"parsing_exception","reason":"unknown query"}],"type":"parsing_exception","reason":"unknown query"},"status":400}`, 400, nil)
+				return server
+			},
+			expectedError:     "v7.Client.Search: doSearch: [400 Bad Request] parsing_exception: unknown query: unknown query",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			transport := test.transport()
+			client := mustNewClientTest(transport).(*esClient)
+			client.retryPolicy = RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+				RetryOnStatus:  []int{429},
+			}
+
+			response, err := client.Search(context.Background(), SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    1,
+			})
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+			transport.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Reads_Retry(t *testing.T) {
+	t.Parallel()
+
+	tooManyRequests := `{"error":{"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":429}`
+
+	tests := []struct {
+		name     string
+		url      string
+		response string
+		call     func(context.Context, Client) error
+	}{
+		{
+			name:     "count",
+			url:      "http://localhost:9200/index1/_count?allow_no_indices=false&ignore_unavailable=false",
+			response: `{"count":1}`,
+			call: func(ctx context.Context, client Client) error {
+				_, err := client.Count(ctx, CountConfig{Indexes: []string{"index1"}})
+				return err
+			},
+		},
+		{
+			name:     "get",
+			url:      "http://localhost:9200/index1/_doc/id-1",
+			response: `{"_index":"index1","_id":"id-1","found":true,"_source":{}}`,
+			call: func(ctx context.Context, client Client) error {
+				_, err := client.Get(ctx, GetConfig{Index: "index1", ID: "id-1"})
+				return err
+			},
+		},
+		{
+			name:     "mget",
+			url:      "http://localhost:9200/index1/_mget",
+			response: `{"docs":[{"_index":"index1","_id":"id-1","found":true,"_source":{}}]}`,
+			call: func(ctx context.Context, client Client) error {
+				_, err := client.MGet(ctx, MGetConfig{Index: "index1", Documents: []MGetDocument{{ID: "id-1"}}})
+				return err
+			},
+		},
+		{
+			name:     "multi search",
+			url:      "http://localhost:9200/_msearch",
+			response: `{"took":1,"responses":[{"took":1,"hits":{"hits":[]}}]}`,
+			call: func(ctx context.Context, client Client) error {
+				_, err := client.MultiSearch(ctx, []SearchConfig{{Indexes: []string{"index1"}, Size: 1}})
+				return err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport := new(mockTransport)
+			transport.On("RoundTrip", test.url, mock.Anything).Once().Return(tooManyRequests, 429, nil)
+			transport.On("RoundTrip", test.url, mock.Anything).Once().Return(test.response, 200, nil)
+			client := mustNewClientTest(transport).(*esClient)
+			client.retryPolicy = RetryPolicy{
+				MaxAttempts:    2,
+				InitialBackoff: time.Millisecond,
+				RetryOnStatus:  []int{429},
+			}
+
+			assert.NoError(t, test.call(context.Background(), client))
+			transport.AssertExpectations(t)
+		})
+	}
+}
+
+// Test_ScrollCursor_Next_NotRetried shows that a page of a scroll is never
+// retried: elasticsearch moves the scroll forward when it runs the request,
+// so a retry after a lost response would skip a page.
+func Test_ScrollCursor_Next_NotRetried(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&scroll=60000ms&size=1&track_total_hits=false",
+		mock.Anything,
+	).Once().Return(`{"_scroll_id":"scroll-1","took":1,"hits":{"hits":[{"_id":"id-1"}]}}`, 200, nil)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/_search/scroll",
+		`{"scroll":"60000ms","scroll_id":"scroll-1"}`,
+	).Once().Return(`{"error":{"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":429}`, 429, nil)
+	client := mustNewClientTest(transport).(*esClient)
+	client.retryPolicy = RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		RetryOnStatus:  []int{429},
+	}
+
+	cursor, err := client.Scroll(context.Background(), SearchConfig{Indexes: []string{"index1"}, Size: 1}, time.Minute)
+	require.NoError(t, err)
+	_, err = cursor.Next(context.Background())
+	require.NoError(t, err)
+
+	_, err = cursor.Next(context.Background())
+	assert.Equal(t, ErrCodeTooManyRequests, errors.GetCode(err))
+	transport.AssertExpectations(t)
+}
@@ -0,0 +1,211 @@
+package v7
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+// ScrollCursor reads the pages of a scroll opened by the Scroll method.
+//
+// A ScrollCursor is not safe for concurrent use.
+type ScrollCursor interface {
+	// Next returns the next page of hits. It returns ErrEndOfScroll when
+	// there are no more pages.
+	Next(ctx context.Context) (SearchResponse, error)
+	// Close releases the scroll context in elasticsearch. It is safe to
+	// call Close more than once.
+	Close(ctx context.Context) error
+}
+
+type scrollCursor struct {
+	client    *esClient
+	keepAlive time.Duration
+	scrollID  string
+	firstPage *SearchResponse
+	done      bool
+}
+
+// Scroll opens a scroll for the search described by @config, keeping it
+// alive for @keepAlive between pages. The @config's Size is the page size and
+// must be greater than zero. SearchAfter, SearchBefore and PointInTime are
+// not supported, as a scroll only pages forward from the first hit.
+//
+// The first page is fetched right away. The returned ScrollCursor must be
+// closed to release the scroll context.
+func (c *esClient) Scroll(
+	ctx context.Context,
+	config SearchConfig,
+	keepAlive time.Duration,
+) (ScrollCursor, error) {
+	const op = errors.Op("v7.Client.Scroll")
+
+	if config.Size <= 0 {
+		return nil, errors.E(op, requiredFieldError("Size"), ErrCodeBadRequest)
+	}
+	if keepAlive <= 0 {
+		return nil, errors.E(op, requiredFieldError("keepAlive"), ErrCodeBadRequest)
+	}
+	if config.SearchAfter != "" || config.SearchBefore != "" || config.PointInTime != nil {
+		return nil, errors.E(
+			op,
+			"scroll does not support search_after, search_before nor point in time",
+			ErrCodeBadRequest,
+		)
+	}
+
+	enrichLogWithIndexes(ctx, config.Indexes)
+
+	cursor := &scrollCursor{
+		client:    c,
+		keepAlive: keepAlive,
+	}
+
+	response, err := c.doSearch(ctx, config, c.client.Search.WithScroll(keepAlive))
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+
+	page, err := cursor.parsePage(ctx, response)
+	if err != nil {
+		_ = cursor.Close(ctx)
+		return nil, errors.E(op, err)
+	}
+	cursor.firstPage = &page
+
+	return cursor, nil
+}
+
+// Next is not retried by the RetryPolicy: elasticsearch moves the scroll
+// forward when it runs the request, so retrying a request whose response was
+// lost would silently skip a page.
+func (s *scrollCursor) Next(ctx context.Context) (SearchResponse, error) {
+	const op = errors.Op("v7.ScrollCursor.Next")
+
+	page, err := s.nextPage(ctx)
+	if err != nil {
+		return SearchResponse{}, errors.E(op, err)
+	}
+
+	if len(page.Hits) == 0 {
+		s.done = true
+		return SearchResponse{}, errors.E(op, ErrEndOfScroll)
+	}
+
+	return page, nil
+}
+
+func (s *scrollCursor) nextPage(ctx context.Context) (SearchResponse, error) {
+	if s.firstPage != nil {
+		page := *s.firstPage
+		s.firstPage = nil
+		return page, nil
+	}
+
+	if s.done || s.scrollID == "" {
+		return SearchResponse{}, nil
+	}
+
+	response, err := s.doScroll(ctx)
+	if err != nil {
+		return SearchResponse{}, err
+	}
+
+	return s.parsePage(ctx, response)
+}
+
+func (s *scrollCursor) doScroll(ctx context.Context) (*esapi.Response, error) {
+	const op = errors.Op("doScroll")
+
+	body, err := encodeBody(map[string]string{
+		"scroll":    formatKeepAlive(s.keepAlive),
+		"scroll_id": s.scrollID,
+	})
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	response, err := s.client.client.Scroll(
+		s.client.client.Scroll.WithContext(ctx),
+		s.client.client.Scroll.WithBody(body),
+	)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+// parsePage parses the @response and keeps its scroll ID, even if the page
+// failed, so the scroll can still be cleared.
+func (s *scrollCursor) parsePage(ctx context.Context, response *esapi.Response) (SearchResponse, error) {
+	defer response.Body.Close()
+
+	page, err := parseResponse(ctx, response)
+	if page.ScrollID != "" {
+		s.scrollID = page.ScrollID
+	}
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeUnexpectedResponse)
+		}
+		return SearchResponse{}, err
+	}
+
+	return page, nil
+}
+
+func (s *scrollCursor) Close(ctx context.Context) error {
+	const op = errors.Op("v7.ScrollCursor.Close")
+
+	s.done = true
+	s.firstPage = nil
+	if s.scrollID == "" {
+		return nil
+	}
+
+	body, err := encodeBody(map[string][]string{
+		"scroll_id": {s.scrollID},
+	})
+	if err != nil {
+		return errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	response, err := s.client.client.ClearScroll(
+		s.client.client.ClearScroll.WithContext(ctx),
+		s.client.client.ClearScroll.WithBody(body),
+	)
+	if err != nil {
+		return errors.E(op, err, ErrCodeBadGateway)
+	}
+	defer response.Body.Close()
+
+	s.scrollID = ""
+
+	// elasticsearch replies 404 when the scroll has already expired.
+	if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return errors.E(op, err)
+	}
+
+	return nil
+}
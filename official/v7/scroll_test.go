@@ -0,0 +1,146 @@
+package v7
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Scroll(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		config            SearchConfig
+		transport         *mockTransport
+		expectedIDs       [][]string
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    2,
+				Filter:  Filter{},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&scroll=60000ms&size=2&track_total_hits=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"_scroll_id":"scroll-1","took":1,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"hits":[{"_id":"id-1"},{"_id":"id-2"}]}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search/scroll",
+					`{"scroll":"60000ms","scroll_id":"scroll-1"}`,
+				).Once().Return(
+					`{"_scroll_id":"scroll-2","took":1,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"hits":[{"_id":"id-3"}]}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search/scroll",
+					`{"scroll":"60000ms","scroll_id":"scroll-2"}`,
+				).Once().Return(
+					`{"_scroll_id":"scroll-2","took":1,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"hits":[]}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search/scroll",
+					`{"scroll_id":["scroll-2"]}`,
+				).Once().Return(`{"succeeded":true,"num_freed":1}`, 200, nil)
+				return server
+			}(),
+			expectedIDs:   [][]string{{"id-1", "id-2"}, {"id-3"}},
+			expectedError: "v7.ScrollCursor.Next: end of scroll",
+		},
+		{
+			name: "not all shards replied",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    2,
+				Filter:  Filter{},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&scroll=60000ms&size=2&track_total_hits=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"_scroll_id":"scroll-1","took":1,"_shards":{"total":2,"successful":1,"skipped":0,"failed":1},"hits":{"hits":[{"_id":"id-1"}]}}`,
+					200,
+					nil,
+				)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/_search/scroll",
+					`{"scroll_id":["scroll-1"]}`,
+				).Once().Return(`{"succeeded":true,"num_freed":1}`, 200, nil)
+				return server
+			}(),
+			expectedError:     "v7.Client.Scroll: parseResponse: not all shards replied",
+			expectedErrorCode: ErrCodeBadGateway,
+		},
+		{
+			name: "missing size",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+			},
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.Scroll: [Size] is required",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+		{
+			name: "search before",
+			config: SearchConfig{
+				Indexes:      []string{"index1"},
+				Size:         2,
+				Sort:         Sorters{Sorters: []Sorter{{Field: "ID", Ascending: true}}},
+				SearchBefore: `["id-3"]`,
+			},
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.Scroll: scroll does not support search_after, search_before nor point in time",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			client := mustNewClientTest(test.transport)
+
+			var ids [][]string
+			cursor, err := client.Scroll(ctx, test.config, time.Minute)
+			if err == nil {
+				for {
+					var page SearchResponse
+					page, err = cursor.Next(ctx)
+					if err != nil {
+						break
+					}
+					ids = append(ids, page.IDs)
+				}
+				assert.NoError(t, cursor.Close(ctx))
+				assert.NoError(t, cursor.Close(ctx))
+			}
+
+			assert.ErrorContains(t, err, test.expectedError)
+			assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			assert.Equal(t, test.expectedIDs, ids)
+			test.transport.AssertExpectations(t)
+		})
+	}
+}
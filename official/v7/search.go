@@ -2,13 +2,21 @@ package v7
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	"github.com/arquivei/foundationkit/errors"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
 )
 
 // SearchConfig hold all information to Search method.
+//
+// SearchAfter and SearchBefore take a SearchResponse's Paginator and
+// PreviousPaginator to fetch the next and the previous pages of a sorted
+// search. A search with SearchBefore runs with every Sorter reversed and its
+// hits are reversed back, so they keep the order of Sort.
 type SearchConfig struct {
 	Indexes           []string
 	Size              int
@@ -18,7 +26,14 @@ type SearchConfig struct {
 	TrackTotalHits    bool
 	Sort              Sorters
 	SearchAfter       string
+	SearchBefore      string
 	Aggregation       RequestAggregation
+	Source            SourceFilter
+	StoredFields      []string
+	DocValueFields    []FieldAndFormat
+	Fields            []FieldAndFormat
+	PointInTime       *PointInTime
+	Highlight         *querybuilders.Highlight
 }
 
 func (c *esClient) Search(ctx context.Context, config SearchConfig) (SearchResponse, error) {
@@ -26,10 +41,43 @@ func (c *esClient) Search(ctx context.Context, config SearchConfig) (SearchRespo
 
 	enrichLogWithIndexes(ctx, config.Indexes)
 
-	response, err := c.doSearch(ctx, config)
+	config, err := c.decodePaginator(config)
+	if err != nil {
+		return SearchResponse{}, errors.E(op, err)
+	}
+
+	searchConfig, previousPage, err := previousPageConfig(config)
+	if err != nil {
+		return SearchResponse{}, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	var searchResponse SearchResponse
+	err = c.retryPolicy.do(ctx, func() error {
+		var err error
+		searchResponse, err = c.search(ctx, searchConfig)
+		return err
+	})
+	if err != nil {
+		return SearchResponse{}, errors.E(op, err)
+	}
+
+	if previousPage {
+		searchResponse = reverseSearchResponse(searchResponse)
+	}
+
+	searchResponse, err = c.encodePaginator(config, searchResponse)
 	if err != nil {
 		return SearchResponse{}, errors.E(op, err)
 	}
+
+	return searchResponse, nil
+}
+
+func (c *esClient) search(ctx context.Context, config SearchConfig) (SearchResponse, error) {
+	response, err := c.doSearch(ctx, config)
+	if err != nil {
+		return SearchResponse{}, err
+	}
 	defer response.Body.Close()
 
 	parsedResponse, err := parseResponse(ctx, response)
@@ -37,13 +85,17 @@ func (c *esClient) Search(ctx context.Context, config SearchConfig) (SearchRespo
 		if errors.GetCode(err) == errors.CodeEmpty {
 			err = errors.E(err, ErrCodeUnexpectedResponse)
 		}
-		return SearchResponse{}, errors.E(op, err)
+		return SearchResponse{}, err
 	}
 
 	return parsedResponse, nil
 }
 
-func (c *esClient) doSearch(ctx context.Context, config SearchConfig) (*esapi.Response, error) {
+func (c *esClient) doSearch(
+	ctx context.Context,
+	config SearchConfig,
+	extraOptions ...func(*esapi.SearchRequest),
+) (*esapi.Response, error) {
 	const op = errors.Op("doSearch")
 
 	queryString, err := getQuery(ctx, config)
@@ -51,15 +103,25 @@ func (c *esClient) doSearch(ctx context.Context, config SearchConfig) (*esapi.Re
 		return nil, errors.E(op, err, ErrCodeBadRequest)
 	}
 
-	response, err := c.client.Search(
-		c.client.Search.WithIndex(config.Indexes...),
+	options := []func(*esapi.SearchRequest){
+		c.client.Search.WithContext(ctx),
 		c.client.Search.WithSize(config.Size),
 		c.client.Search.WithBody(strings.NewReader(queryString)),
-		c.client.Search.WithIgnoreUnavailable(config.IgnoreUnavailable),
-		c.client.Search.WithAllowNoIndices(config.AllowNoIndices),
 		c.client.Search.WithTrackTotalHits(config.TrackTotalHits),
 		c.client.Search.WithSort(config.Sort.Strings()...),
-	)
+	}
+	// A search on a point in time must not set the indexes or its options,
+	// they are taken from the point in time.
+	if config.PointInTime == nil {
+		options = append(options,
+			c.client.Search.WithIndex(config.Indexes...),
+			c.client.Search.WithIgnoreUnavailable(config.IgnoreUnavailable),
+			c.client.Search.WithAllowNoIndices(config.AllowNoIndices),
+		)
+	}
+	options = append(options, extraOptions...)
+
+	response, err := c.client.Search(options...)
 	if err != nil {
 		return nil, errors.E(op, err, ErrCodeBadGateway)
 	}
@@ -76,22 +138,22 @@ func (c *esClient) doSearch(ctx context.Context, config SearchConfig) (*esapi.Re
 }
 
 func getQuery(ctx context.Context, config SearchConfig) (string, error) {
-	query, err := buildElasticBoolQuery(config.Filter)
+	body, err := newSearchRequestBody(config)
 	if err != nil {
 		return "", err
 	}
+	return encodeSearchRequestBody(ctx, body)
+}
 
-	var aggsQueryString string
-	if hasAggregations(config.Aggregation) {
-		aggs, err := buildElasticAggsQuery(config.Aggregation)
-		if err != nil {
-			return "", err
-		}
-
-		aggsQueryString = marshalQuery(aggs)
+// encodeSearchRequestBody returns the JSON of the @body and logs it.
+func encodeSearchRequestBody(ctx context.Context, body SearchRequestBody) (string, error) {
+	query, err := json.Marshal(body)
+	if err != nil {
+		return "", err
 	}
 
-	queryString := queryWithSearchAfter(query, aggsQueryString, config.SearchAfter)
-	enrichLogWithQuery(ctx, queryString)
-	return queryString, nil
+	enrichLogWithQuery(ctx, string(query), func() string {
+		return marshalValue(body.redacted())
+	})
+	return string(query), nil
 }
@@ -0,0 +1,91 @@
+package v7
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// Document represents a search hit with its _source decoded into T.
+type Document[T any] struct {
+	ID        string
+	Index     string
+	Score     *float64
+	Sort      []interface{}
+	Source    T
+	Fields    map[string][]interface{}
+	Highlight map[string][]string
+}
+
+// DocumentsResponse represents the response for SearchDocuments function.
+type DocumentsResponse[T any] struct {
+	Documents    []Document[T]
+	Paginator    string
+	Total        int
+	Took         int
+	Aggregations ResponseAggregation
+	// PreviousPaginator is the SearchBefore of the previous page.
+	PreviousPaginator string
+}
+
+// SearchDocuments runs a Search with @config using the @client and decodes
+// the _source of every returned hit into T. Hits without _source are
+// returned with a zero value T.
+func SearchDocuments[T any](
+	ctx context.Context,
+	client Client,
+	config SearchConfig,
+) (DocumentsResponse[T], error) {
+	const op = errors.Op("v7.SearchDocuments")
+
+	response, err := client.Search(ctx, config)
+	if err != nil {
+		return DocumentsResponse[T]{}, errors.E(op, err)
+	}
+
+	documents, err := decodeDocuments[T](response.Hits)
+	if err != nil {
+		return DocumentsResponse[T]{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return DocumentsResponse[T]{
+		Documents:         documents,
+		Paginator:         response.Paginator,
+		Total:             response.Total,
+		Took:              response.Took,
+		Aggregations:      response.Aggregations,
+		PreviousPaginator: response.PreviousPaginator,
+	}, nil
+}
+
+func decodeDocuments[T any](hits []SearchHit) ([]Document[T], error) {
+	const op = errors.Op("decodeDocuments")
+
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	documents := make([]Document[T], 0, len(hits))
+	for _, hit := range hits {
+		var source T
+		if len(hit.Source) > 0 {
+			err := json.Unmarshal(hit.Source, &source)
+			if err != nil {
+				return nil, errors.E(op, err, errors.KV("id", hit.ID))
+			}
+		}
+
+		documents = append(documents, Document[T]{
+			ID:        hit.ID,
+			Index:     hit.Index,
+			Score:     hit.Score,
+			Sort:      hit.Sort,
+			Source:    source,
+			Fields:    hit.Fields,
+			Highlight: hit.Highlight,
+		})
+	}
+
+	return documents, nil
+}
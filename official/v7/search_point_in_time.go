@@ -0,0 +1,111 @@
+package v7
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const shardDocField = "_shard_doc"
+
+// SearchWithPointInTime returns an iterator over all pages of the search
+// described by @config. It opens a point in time on @config's Indexes, pages
+// through it using search_after and closes it when the iteration ends, even
+// if the @ctx is canceled.
+//
+// The @config's Sort is used as is, with a _shard_doc tiebreaker appended
+// when it is missing, so every hit is returned exactly once. The @config's
+// Size is the page size and must be greater than zero. SearchBefore is not
+// supported, as the iteration only pages forward. The point in time is kept
+// alive for @keepAlive between pages.
+//
+// An error is yielded at most once and ends the iteration.
+func SearchWithPointInTime(
+	ctx context.Context,
+	client Client,
+	config SearchConfig,
+	keepAlive time.Duration,
+) iter.Seq2[SearchResponse, error] {
+	const op = errors.Op("v7.SearchWithPointInTime")
+
+	return func(yield func(SearchResponse, error) bool) {
+		if config.Size <= 0 {
+			yield(SearchResponse{}, errors.E(op, requiredFieldError("Size"), ErrCodeBadRequest))
+			return
+		}
+		if config.SearchBefore != "" {
+			yield(SearchResponse{}, errors.E(
+				op,
+				"point in time iteration does not support search_before",
+				ErrCodeBadRequest,
+			))
+			return
+		}
+
+		id, err := client.OpenPointInTime(ctx, OpenPointInTimeConfig{
+			Indexes:           config.Indexes,
+			KeepAlive:         keepAlive,
+			IgnoreUnavailable: config.IgnoreUnavailable,
+		})
+		if err != nil {
+			yield(SearchResponse{}, errors.E(op, err))
+			return
+		}
+		defer func() {
+			closePointInTime(context.WithoutCancel(ctx), client, id)
+		}()
+
+		config.Sort = withShardDocTiebreaker(config.Sort)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(SearchResponse{}, errors.E(op, err))
+				return
+			}
+
+			config.PointInTime = &PointInTime{ID: id, KeepAlive: keepAlive}
+
+			response, err := client.Search(ctx, config)
+			if err != nil {
+				yield(SearchResponse{}, errors.E(op, err))
+				return
+			}
+			if response.PointInTimeID != "" {
+				id = response.PointInTimeID
+			}
+			if len(response.Hits) == 0 {
+				return
+			}
+			if !yield(response, nil) {
+				return
+			}
+			if len(response.Hits) < config.Size || response.Paginator == "" {
+				return
+			}
+
+			config.SearchAfter = response.Paginator
+		}
+	}
+}
+
+func closePointInTime(ctx context.Context, client Client, id string) {
+	err := client.ClosePointInTime(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Failed to close elastic point in time")
+	}
+}
+
+func withShardDocTiebreaker(sort Sorters) Sorters {
+	for _, sorter := range sort.Sorters {
+		if sorter.Field == shardDocField {
+			return sort
+		}
+	}
+
+	sorters := make([]Sorter, 0, len(sort.Sorters)+1)
+	sorters = append(sorters, sort.Sorters...)
+	sorters = append(sorters, Sorter{Field: shardDocField, Ascending: true})
+	return Sorters{Sorters: sorters}
+}
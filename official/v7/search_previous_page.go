@@ -0,0 +1,41 @@
+package v7
+
+import (
+	"slices"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// previousPageConfig returns the config of the search for the page before
+// the @config's SearchBefore, and whether it is one. As search_after only
+// pages forward, the search runs from the SearchBefore with every Sorter
+// reversed, so its hits must be reversed back by reverseSearchResponse.
+func previousPageConfig(config SearchConfig) (SearchConfig, bool, error) {
+	const op = errors.Op("previousPageConfig")
+
+	if config.SearchBefore == "" {
+		return config, false, nil
+	}
+	if config.SearchAfter != "" {
+		return SearchConfig{}, false, errors.E(op, ErrSearchBeforeWithSearchAfter)
+	}
+	if len(config.Sort.Sorters) == 0 {
+		return SearchConfig{}, false, errors.E(op, requiredFieldError("Sort"))
+	}
+
+	config.SearchAfter = config.SearchBefore
+	config.SearchBefore = ""
+	config.Sort = config.Sort.reversed()
+	return config, true, nil
+}
+
+// reverseSearchResponse puts the hits of a search run by previousPageConfig
+// back in the requested order, which also swaps its paginators.
+func reverseSearchResponse(response SearchResponse) SearchResponse {
+	response.IDs = slices.Clone(response.IDs)
+	slices.Reverse(response.IDs)
+	response.Hits = slices.Clone(response.Hits)
+	slices.Reverse(response.Hits)
+	response.Paginator, response.PreviousPaginator = response.PreviousPaginator, response.Paginator
+	return response
+}
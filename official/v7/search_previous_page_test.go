@@ -0,0 +1,172 @@
+package v7
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Search_previousPage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		config            SearchConfig
+		transport         *mockTransport
+		expectedIDs       []string
+		expectedNext      string
+		expectedPrevious  string
+		expectedError     error
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "reversed sort and hits",
+			config: SearchConfig{
+				Indexes:      []string{"index1"},
+				Size:         2,
+				Sort:         Sorters{Sorters: []Sorter{{Field: "Date", Ascending: false}, {Field: "ID", Ascending: true}}},
+				SearchBefore: `[1700000000000,"id-5"]`,
+			},
+			transport: func() *mockTransport {
+				transport := new(mockTransport)
+				transport.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=2&sort=Date%3Aasc%2CID%3Adesc&track_total_hits=false",
+					`{"query":{"match_all":{}},"search_after":[1700000000000,"id-5"]}`,
+				).Once().Return(
+					`{"took":1,"_shards":{"total":1,"successful":1},"hits":{"hits":[`+
+						`{"_index":"index1","_id":"id-4","sort":[1700000000000,"id-4"]},`+
+						`{"_index":"index1","_id":"id-3","sort":[1800000000000,"id-3"]}]}}`,
+					200,
+					nil,
+				)
+				return transport
+			}(),
+			expectedIDs:      []string{"id-3", "id-4"},
+			expectedNext:     `[1700000000000,"id-4"]`,
+			expectedPrevious: `[1800000000000,"id-3"]`,
+		},
+		{
+			name: "search before with search after",
+			config: SearchConfig{
+				Indexes:      []string{"index1"},
+				Size:         2,
+				Sort:         Sorters{Sorters: []Sorter{{Field: "ID", Ascending: true}}},
+				SearchAfter:  `["id-1"]`,
+				SearchBefore: `["id-5"]`,
+			},
+			transport:         new(mockTransport),
+			expectedError:     ErrSearchBeforeWithSearchAfter,
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+		{
+			name: "search before without sort",
+			config: SearchConfig{
+				Indexes:      []string{"index1"},
+				Size:         2,
+				SearchBefore: `["id-5"]`,
+			},
+			transport:         new(mockTransport),
+			expectedErrorCode: ErrCodeBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			defer test.transport.AssertExpectations(t)
+
+			client := mustNewClientTest(test.transport)
+			response, err := client.Search(context.Background(), test.config)
+			if test.expectedErrorCode != errors.CodeEmpty {
+				assert.Error(t, err)
+				if test.expectedError != nil {
+					assert.True(t, errors.Is(err, test.expectedError), "unexpected error: %v", err)
+				}
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedIDs, response.IDs)
+			assert.Equal(t, test.expectedIDs[0], response.Hits[0].ID)
+			assert.Equal(t, test.expectedNext, response.Paginator)
+			assert.Equal(t, test.expectedPrevious, response.PreviousPaginator)
+		})
+	}
+}
+
+func Test_MultiSearch_previousPage(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/_msearch",
+		"{\"allow_no_indices\":false,\"ignore_unavailable\":false,\"index\":[\"index1\"]}\n"+
+			"{\"query\":{\"match_all\":{}},\"search_after\":[\"id-5\"],\"sort\":[{\"ID\":{\"order\":\"desc\"}}],\"size\":2,\"track_total_hits\":false}\n",
+	).Once().Return(
+		`{"took":1,"responses":[{"took":1,"_shards":{"total":1,"successful":1},"hits":{"hits":[`+
+			`{"_index":"index1","_id":"id-4","sort":["id-4"]},{"_index":"index1","_id":"id-3","sort":["id-3"]}]}}]}`,
+		200,
+		nil,
+	)
+	defer transport.AssertExpectations(t)
+
+	client := mustNewClientTest(transport)
+	response, err := client.MultiSearch(context.Background(), []SearchConfig{{
+		Indexes:      []string{"index1"},
+		Size:         2,
+		Sort:         Sorters{Sorters: []Sorter{{Field: "ID", Ascending: true}}},
+		SearchBefore: `["id-5"]`,
+	}})
+	require.NoError(t, err)
+	require.NoError(t, response.Errors[0])
+	assert.Equal(t, []string{"id-3", "id-4"}, response.Responses[0].IDs)
+	assert.Equal(t, `["id-4"]`, response.Responses[0].Paginator)
+	assert.Equal(t, `["id-3"]`, response.Responses[0].PreviousPaginator)
+}
+
+func Test_Search_previousPageWithPaginatorCodec(t *testing.T) {
+	t.Parallel()
+
+	codec, err := NewPaginatorCodec([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	config := SearchConfig{
+		Indexes: []string{"index1"},
+		Size:    2,
+		Sort:    Sorters{Sorters: []Sorter{{Field: "ID", Ascending: true}}},
+	}
+	config.SearchBefore, err = codec.Encode(config, `["id-5"]`)
+	require.NoError(t, err)
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=2&sort=ID%3Adesc&track_total_hits=false",
+		`{"query":{"match_all":{}},"search_after":["id-5"]}`,
+	).Once().Return(
+		`{"took":1,"_shards":{"total":1,"successful":1},"hits":{"hits":[`+
+			`{"_index":"index1","_id":"id-4","sort":["id-4"]},{"_index":"index1","_id":"id-3","sort":["id-3"]}]}}`,
+		200,
+		nil,
+	)
+	defer transport.AssertExpectations(t)
+
+	client := mustNewClientTest(transport).(*esClient)
+	client.paginatorCodec = codec
+
+	response, err := client.Search(context.Background(), config)
+	require.NoError(t, err)
+
+	next, err := codec.Decode(config, response.Paginator)
+	require.NoError(t, err)
+	assert.Equal(t, `["id-4"]`, next)
+	previous, err := codec.Decode(config, response.PreviousPaginator)
+	require.NoError(t, err)
+	assert.Equal(t, `["id-3"]`, previous)
+}
@@ -2,12 +2,10 @@ package v7
 
 import (
 	"encoding/json"
-	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/arquivei/foundationkit/errors"
-	"github.com/rs/zerolog/log"
 
 	"github.com/arquivei/elasticutil/official/v7/querybuilders"
 )
@@ -551,28 +549,12 @@ func extractSliceFromInterface[T any](input interface{}) []interface{} {
 	return is
 }
 
-func queryWithSearchAfter(q querybuilders.Query, aggs string, searchAfter string) string {
-	var b strings.Builder
-
-	b.WriteString(`{"query":`)
-
-	b.WriteString(marshalQuery(q))
-
-	if len(aggs) > 0 {
-		b.WriteString(", ")
-		b.WriteString(fmt.Sprintf(`	"aggs": %s`, aggs))
-	}
-
-	if len(searchAfter) > 0 {
-		b.WriteString(", ")
-		b.WriteString(fmt.Sprintf(`	"search_after": %s`, searchAfter))
+func marshalValue(value interface{}) string {
+	v, e := json.Marshal(value)
+	if e != nil {
+		return e.Error()
 	}
-
-	b.WriteString("}")
-
-	log.Debug().Str("elastic_query", b.String()).Msg("Elastic query")
-
-	return b.String()
+	return string(v)
 }
 
 func hasAggregations(aggregation RequestAggregation) bool {
@@ -48,7 +48,7 @@ func Test_Search(t *testing.T) {
 						},
 					},
 				},
-				SearchAfter: `{"paginator"}`,
+				SearchAfter: `["paginator"]`,
 				Aggregation: getMockAggregations(),
 			},
 			transport: func() *mockTransport {
@@ -56,7 +56,7 @@ func Test_Search(t *testing.T) {
 				server.On(
 					"RoundTrip",
 					"http://localhost:9200/index1,index2/_search?allow_no_indices=true&ignore_unavailable=true&size=19&sort=Date%3Aasc%2CID%3Adesc&track_total_hits=true",
-					`{"query":{"bool":{"must":[{"terms":{"Name":["John","Mary"]}},{"terms":{"Age":[16,17,18,25,26]}},{"term":{"HasCovid":true}},{"range":{"CreatedAt":{"from":"2020-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2021-11-28T15:27:39.000000049Z"}}},{"range":{"Age":{"from":15,"include_lower":true,"include_upper":true,"to":30}}},{"range":{"Age":{"from":0.5,"include_lower":true,"include_upper":true,"to":1.9}}},{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["cough"]}},{"range":{"Covid.Date":{"from":"2019-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2020-11-28T15:27:39.000000049Z"}}}]}}}},{"bool":{"should":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"John","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Mary","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Rebecca","type":"phrase_prefix"}}]}},{"bool":{"must":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Lennon","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"McCartney","type":"phrase_prefix"}}]}},{"bool":{"should":[{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Beatles","type":"best_fields"}},{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Stones","type":"best_fields"}}]}},{"bool":{"must":{"term":{"Name":"John"}}}},{"nested":{"path":"Covid","query":{"exists":{"field":"Covid"}}}},{"exists":{"field":"Age"}}],"must_not":[{"terms":{"Name":["Lary"]}},{"range":{"Age":{"from":29,"include_lower":true,"include_upper":true,"to":30}}}]}}, 	"aggs": {"count_aggregation":{"value_count":{"field":"count_field"}},"max_aggregation_name":{"max":{"field":"max_field"}},"max_aggregation_name_2":{"max":{"field":"max_field_2"}},"min_aggregation_name":{"min":{"field":"min_field"}},"some_histogram_agg":{"aggs":{"max_agg_in_histogram_name":{"max":{"field":"max_in_histogram_field"}},"min_agg_in_histogram_name":{"min":{"field":"min_in_histogram_field"}}},"date_histogram":{"field":"EmissionDate","interval":"month"}},"some_term_agg":{"aggs":{"min_agg_in_term_name":{"min":{"field":"min_in_term_field"}}},"terms":{"field":"CompanyRole","show_term_doc_count_error":true}},"sum_aggregation_name":{"sum":{"field":"sum_field"}}}, 	"search_after": {"paginator"}}`,
+					`{"query":{"bool":{"must":[{"terms":{"Name":["John","Mary"]}},{"terms":{"Age":[16,17,18,25,26]}},{"term":{"HasCovid":true}},{"range":{"CreatedAt":{"from":"2020-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2021-11-28T15:27:39.000000049Z"}}},{"range":{"Age":{"from":15,"include_lower":true,"include_upper":true,"to":30}}},{"range":{"Age":{"from":0.5,"include_lower":true,"include_upper":true,"to":1.9}}},{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["cough"]}},{"range":{"Covid.Date":{"from":"2019-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2020-11-28T15:27:39.000000049Z"}}}]}}}},{"bool":{"should":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"John","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Mary","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Rebecca","type":"phrase_prefix"}}]}},{"bool":{"must":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Lennon","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"McCartney","type":"phrase_prefix"}}]}},{"bool":{"should":[{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Beatles","type":"best_fields"}},{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Stones","type":"best_fields"}}]}},{"bool":{"must":{"term":{"Name":"John"}}}},{"nested":{"path":"Covid","query":{"exists":{"field":"Covid"}}}},{"exists":{"field":"Age"}}],"must_not":[{"terms":{"Name":["Lary"]}},{"range":{"Age":{"from":29,"include_lower":true,"include_upper":true,"to":30}}}]}},"aggs":{"count_aggregation":{"value_count":{"field":"count_field"}},"max_aggregation_name":{"max":{"field":"max_field"}},"max_aggregation_name_2":{"max":{"field":"max_field_2"}},"min_aggregation_name":{"min":{"field":"min_field"}},"some_histogram_agg":{"aggs":{"max_agg_in_histogram_name":{"max":{"field":"max_in_histogram_field"}},"min_agg_in_histogram_name":{"min":{"field":"min_in_histogram_field"}}},"date_histogram":{"field":"EmissionDate","interval":"month"}},"some_term_agg":{"aggs":{"min_agg_in_term_name":{"min":{"field":"min_in_term_field"}}},"terms":{"field":"CompanyRole","show_term_doc_count_error":true}},"sum_aggregation_name":{"sum":{"field":"sum_field"}}},"search_after":["paginator"]}`,
 				).Once().Return(
 					`{"took":10,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":2},"max_score":null,"hits":[{"_index":"tiramisu_cte-2022101","_id":"elastic-id-1","_score":null,"sort":["pag2"]},{"_index":"tiramisu_cte-2019","_id":"elastic-id-2","_score":null,"sort":["pag3"]}]}}`,
 					200,
@@ -66,10 +66,149 @@ func Test_Search(t *testing.T) {
 				return server
 			}(),
 			expectedResponse: SearchResponse{
-				IDs:       []string{"elastic-id-1", "elastic-id-2"},
-				Paginator: `["pag3"]`,
-				Total:     2,
-				Took:      10,
+				IDs: []string{"elastic-id-1", "elastic-id-2"},
+				Hits: []SearchHit{
+					{
+						ID:    "elastic-id-1",
+						Index: "tiramisu_cte-2022101",
+						Sort:  []interface{}{"pag2"},
+					},
+					{
+						ID:    "elastic-id-2",
+						Index: "tiramisu_cte-2019",
+						Sort:  []interface{}{"pag3"},
+					},
+				},
+				Paginator:         `["pag3"]`,
+				PreviousPaginator: `["pag2"]`,
+				Total:             2,
+				Took:              10,
+			},
+		},
+		{
+			name: "success with source filtering and fields",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    1,
+				Source: SourceFilter{
+					Includes: []string{"Name", "Covid.*"},
+					Excludes: []string{"Covid.Date"},
+				},
+				StoredFields: []string{"_id"},
+				DocValueFields: []FieldAndFormat{
+					{Field: "Age"},
+					{Field: "CreatedAt", Format: "epoch_millis"},
+				},
+				Fields: []FieldAndFormat{
+					{Field: "Name"},
+				},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false",
+					`{"query":{"match_all":{}},"_source":{"excludes":["Covid.Date"],"includes":["Name","Covid.*"]},"stored_fields":["_id"],"docvalue_fields":["Age",{"field":"CreatedAt","format":"epoch_millis"}],"fields":["Name"]}`,
+				).Once().Return(
+					`{"took":10,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":1},"max_score":1.0,"hits":[{"_index":"index1","_id":"elastic-id-1","_score":1.0,"_source":{"Name":"John"},"fields":{"Name":["John"],"Age":[27],"CreatedAt":["1606577259000"]}}]}}`,
+					200,
+					nil,
+				)
+
+				return server
+			}(),
+			expectedResponse: SearchResponse{
+				IDs: []string{"elastic-id-1"},
+				Hits: []SearchHit{
+					{
+						ID:     "elastic-id-1",
+						Index:  "index1",
+						Score:  ref.Of(1.0),
+						Source: []byte(`{"Name":"John"}`),
+						Fields: map[string][]interface{}{
+							"Name":      {"John"},
+							"Age":       {float64(27)},
+							"CreatedAt": {"1606577259000"},
+						},
+					},
+				},
+				Total: 1,
+				Took:  10,
+			},
+		},
+		{
+			name: "success with source disabled",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    1,
+				Source:  SourceFilter{Disabled: true},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false",
+					`{"query":{"match_all":{}},"_source":false}`,
+				).Once().Return(
+					`{"took":10,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":1},"max_score":1.0,"hits":[{"_index":"index1","_id":"elastic-id-1","_score":1.0}]}}`,
+					200,
+					nil,
+				)
+
+				return server
+			}(),
+			expectedResponse: SearchResponse{
+				IDs: []string{"elastic-id-1"},
+				Hits: []SearchHit{
+					{
+						ID:    "elastic-id-1",
+						Index: "index1",
+						Score: ref.Of(1.0),
+					},
+				},
+				Total: 1,
+				Took:  10,
+			},
+		},
+		{
+			name: "success with highlight",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    1,
+				Highlight: querybuilders.NewHighlight().
+					Fields(querybuilders.NewHighlighterField("Name").NumOfFragments(2)).
+					FragmentSize(50).
+					PreTags("<b>").
+					PostTags("</b>"),
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false",
+					`{"query":{"match_all":{}},"highlight":{"fields":{"Name":{"number_of_fragments":2}},"fragment_size":50,"post_tags":["\u003c/b\u003e"],"pre_tags":["\u003cb\u003e"]}}`,
+				).Once().Return(
+					`{"took":10,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":1},"max_score":1.0,"hits":[{"_index":"index1","_id":"elastic-id-1","_score":1.0,"highlight":{"Name":["<b>John</b> Lennon"]}}]}}`,
+					200,
+					nil,
+				)
+
+				return server
+			}(),
+			expectedResponse: SearchResponse{
+				IDs: []string{"elastic-id-1"},
+				Hits: []SearchHit{
+					{
+						ID:    "elastic-id-1",
+						Index: "index1",
+						Score: ref.Of(1.0),
+						Highlight: map[string][]string{
+							"Name": {"<b>John</b> Lennon"},
+						},
+					},
+				},
+				Total: 1,
+				Took:  10,
 			},
 		},
 		{
@@ -93,14 +232,14 @@ func Test_Search(t *testing.T) {
 						},
 					},
 				},
-				SearchAfter: `{"paginator"}`,
+				SearchAfter: `["paginator"]`,
 			},
 			transport: func() *mockTransport {
 				server := new(mockTransport)
 				server.On(
 					"RoundTrip",
 					"http://localhost:9200/index1,index2/_search?allow_no_indices=true&ignore_unavailable=true&size=19&sort=Date%3Aasc%2CID%3Adesc&track_total_hits=true",
-					`{"query":{"bool":{"must":[{"terms":{"Name":["John","Mary"]}},{"terms":{"Age":[16,17,18,25,26]}},{"term":{"HasCovid":true}},{"range":{"CreatedAt":{"from":"2020-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2021-11-28T15:27:39.000000049Z"}}},{"range":{"Age":{"from":15,"include_lower":true,"include_upper":true,"to":30}}},{"range":{"Age":{"from":0.5,"include_lower":true,"include_upper":true,"to":1.9}}},{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["cough"]}},{"range":{"Covid.Date":{"from":"2019-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2020-11-28T15:27:39.000000049Z"}}}]}}}},{"bool":{"should":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"John","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Mary","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Rebecca","type":"phrase_prefix"}}]}},{"bool":{"must":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Lennon","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"McCartney","type":"phrase_prefix"}}]}},{"bool":{"should":[{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Beatles","type":"best_fields"}},{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Stones","type":"best_fields"}}]}},{"bool":{"must":{"term":{"Name":"John"}}}},{"nested":{"path":"Covid","query":{"exists":{"field":"Covid"}}}},{"exists":{"field":"Age"}}],"must_not":[{"terms":{"Name":["Lary"]}},{"range":{"Age":{"from":29,"include_lower":true,"include_upper":true,"to":30}}}]}}, 	"search_after": {"paginator"}}`,
+					`{"query":{"bool":{"must":[{"terms":{"Name":["John","Mary"]}},{"terms":{"Age":[16,17,18,25,26]}},{"term":{"HasCovid":true}},{"range":{"CreatedAt":{"from":"2020-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2021-11-28T15:27:39.000000049Z"}}},{"range":{"Age":{"from":15,"include_lower":true,"include_upper":true,"to":30}}},{"range":{"Age":{"from":0.5,"include_lower":true,"include_upper":true,"to":1.9}}},{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["cough"]}},{"range":{"Covid.Date":{"from":"2019-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2020-11-28T15:27:39.000000049Z"}}}]}}}},{"bool":{"should":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"John","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Mary","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Rebecca","type":"phrase_prefix"}}]}},{"bool":{"must":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Lennon","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"McCartney","type":"phrase_prefix"}}]}},{"bool":{"should":[{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Beatles","type":"best_fields"}},{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Stones","type":"best_fields"}}]}},{"bool":{"must":{"term":{"Name":"John"}}}},{"nested":{"path":"Covid","query":{"exists":{"field":"Covid"}}}},{"exists":{"field":"Age"}}],"must_not":[{"terms":{"Name":["Lary"]}},{"range":{"Age":{"from":29,"include_lower":true,"include_upper":true,"to":30}}}]}},"search_after":["paginator"]}`,
 				).Once().Return(
 					`{"took":10,"_shards":{"total":1,"successful":0,"skipped":0,"failed":1},"hits":{"total":{"value":2},"max_score":null,"hits":[{"_index":"tiramisu_cte-2022101","_id":"elastic-id-1","_score":null,"sort":["pag2"]},{"_index":"tiramisu_cte-2019","_id":"elastic-id-2","_score":null,"sort":["pag3"]}]}}`,
 					200,
@@ -133,14 +272,14 @@ func Test_Search(t *testing.T) {
 						},
 					},
 				},
-				SearchAfter: `{"paginator"}`,
+				SearchAfter: `["paginator"]`,
 			},
 			transport: func() *mockTransport {
 				server := new(mockTransport)
 				server.On(
 					"RoundTrip",
 					"http://localhost:9200/index1,index2/_search?allow_no_indices=true&ignore_unavailable=true&size=19&sort=Date%3Aasc%2CID%3Adesc&track_total_hits=true",
-					`{"query":{"bool":{"must":[{"terms":{"Name":["John","Mary"]}},{"terms":{"Age":[16,17,18,25,26]}},{"term":{"HasCovid":true}},{"range":{"CreatedAt":{"from":"2020-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2021-11-28T15:27:39.000000049Z"}}},{"range":{"Age":{"from":15,"include_lower":true,"include_upper":true,"to":30}}},{"range":{"Age":{"from":0.5,"include_lower":true,"include_upper":true,"to":1.9}}},{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["cough"]}},{"range":{"Covid.Date":{"from":"2019-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2020-11-28T15:27:39.000000049Z"}}}]}}}},{"bool":{"should":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"John","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Mary","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Rebecca","type":"phrase_prefix"}}]}},{"bool":{"must":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Lennon","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"McCartney","type":"phrase_prefix"}}]}},{"bool":{"should":[{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Beatles","type":"best_fields"}},{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Stones","type":"best_fields"}}]}},{"bool":{"must":{"term":{"Name":"John"}}}},{"nested":{"path":"Covid","query":{"exists":{"field":"Covid"}}}},{"exists":{"field":"Age"}}],"must_not":[{"terms":{"Name":["Lary"]}},{"range":{"Age":{"from":29,"include_lower":true,"include_upper":true,"to":30}}}]}}, 	"search_after": {"paginator"}}`,
+					`{"query":{"bool":{"must":[{"terms":{"Name":["John","Mary"]}},{"terms":{"Age":[16,17,18,25,26]}},{"term":{"HasCovid":true}},{"range":{"CreatedAt":{"from":"2020-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2021-11-28T15:27:39.000000049Z"}}},{"range":{"Age":{"from":15,"include_lower":true,"include_upper":true,"to":30}}},{"range":{"Age":{"from":0.5,"include_lower":true,"include_upper":true,"to":1.9}}},{"nested":{"path":"Covid","query":{"bool":{"must":[{"terms":{"Covid.Symptom":["cough"]}},{"range":{"Covid.Date":{"from":"2019-11-28T15:27:39.000000049Z","include_lower":true,"include_upper":true,"to":"2020-11-28T15:27:39.000000049Z"}}}]}}}},{"bool":{"should":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"John","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Mary","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Rebecca","type":"phrase_prefix"}}]}},{"bool":{"must":[{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"Lennon","type":"phrase_prefix"}},{"multi_match":{"fields":["Name","SocialName"],"max_expansions":1024,"query":"McCartney","type":"phrase_prefix"}}]}},{"bool":{"should":[{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Beatles","type":"best_fields"}},{"multi_match":{"fields":["Any"],"max_expansions":1024,"query":"Stones","type":"best_fields"}}]}},{"bool":{"must":{"term":{"Name":"John"}}}},{"nested":{"path":"Covid","query":{"exists":{"field":"Covid"}}}},{"exists":{"field":"Age"}}],"must_not":[{"terms":{"Name":["Lary"]}},{"range":{"Age":{"from":29,"include_lower":true,"include_upper":true,"to":30}}}]}},"search_after":["paginator"]}`,
 				).Once().Return(
 					`{"took":10,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":2},"max_score":null,"hits":[{"_index":"tiramisu_cte-2022101","_id":"elastic-id-1","_score":null,"sort":["pag2"]},{"_index":"tiramisu_cte-2019","_id":"elastic-id-2","_score":null,"sort":["pag3"]}]}}`,
 					200,
@@ -152,6 +291,18 @@ func Test_Search(t *testing.T) {
 			expectedError:     "v7.Client.Search: doSearch: elastic error",
 			expectedErrorCode: ErrCodeBadGateway,
 		},
+		{
+			name: "invalid search after",
+			config: SearchConfig{
+				Indexes:     []string{"index1"},
+				Size:        10,
+				Sort:        Sorters{Sorters: []Sorter{{Field: "Date", Ascending: true}}},
+				SearchAfter: `{"paginator"}`,
+			},
+			transport:         new(mockTransport),
+			expectedError:     "v7.Client.Search: doSearch: newSearchRequestBody: search after must be a JSON array",
+			expectedErrorCode: ErrCodeBadRequest,
+		},
 	}
 
 	for _, test := range tests {
@@ -172,13 +323,115 @@ func Test_Search(t *testing.T) {
 	}
 }
 
+func Test_SearchDocuments(t *testing.T) {
+	t.Parallel()
+
+	type document struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	tests := []struct {
+		name              string
+		config            SearchConfig
+		transport         *mockTransport
+		expectedResponse  DocumentsResponse[document]
+		expectedError     string
+		expectedErrorCode errors.Code
+	}{
+		{
+			name: "success",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    2,
+				Sort: Sorters{
+					Sorters: []Sorter{{Field: "age", Ascending: true}},
+				},
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=2&sort=age%3Aasc&track_total_hits=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"took":3,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":2},"max_score":null,"hits":[{"_index":"index1","_id":"id-1","_score":null,"_source":{"name":"John","age":27},"sort":[27]},{"_index":"index1","_id":"id-2","_score":1.5,"_source":{"name":"Mary","age":31},"sort":[31]}]}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedResponse: DocumentsResponse[document]{
+				Documents: []Document[document]{
+					{
+						ID:     "id-1",
+						Index:  "index1",
+						Sort:   []interface{}{float64(27)},
+						Source: document{Name: "John", Age: 27},
+					},
+					{
+						ID:     "id-2",
+						Index:  "index1",
+						Score:  ref.Of(1.5),
+						Sort:   []interface{}{float64(31)},
+						Source: document{Name: "Mary", Age: 31},
+					},
+				},
+				Paginator:         "[31]",
+				PreviousPaginator: "[27]",
+				Total:             2,
+				Took:              3,
+			},
+		},
+		{
+			name: "source does not match document type",
+			config: SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    1,
+			},
+			transport: func() *mockTransport {
+				server := new(mockTransport)
+				server.On(
+					"RoundTrip",
+					"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&track_total_hits=false",
+					`{"query":{"match_all":{}}}`,
+				).Once().Return(
+					`{"took":3,"_shards":{"total":1,"successful":1,"skipped":0,"failed":0},"hits":{"total":{"value":1},"max_score":null,"hits":[{"_index":"index1","_id":"id-1","_score":null,"_source":{"name":"John","age":"27"}}]}}`,
+					200,
+					nil,
+				)
+				return server
+			}(),
+			expectedError:     "v7.SearchDocuments: decodeDocuments: json: cannot unmarshal string into Go struct field document.age of type int [id=id-1]",
+			expectedErrorCode: ErrCodeUnexpectedResponse,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			client := mustNewClientTest(test.transport)
+			response, err := SearchDocuments[document](context.Background(), client, test.config)
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedError)
+				assert.Equal(t, test.expectedErrorCode, errors.GetCode(err))
+			}
+			assert.Equal(t, test.expectedResponse, response)
+		})
+	}
+}
+
 type mockTransport struct {
 	mock.Mock
 }
 
 func (m *mockTransport) RoundTrip(r *http.Request) (*http.Response, error) {
 	buf := new(strings.Builder)
-	io.Copy(buf, r.Body)
+	if r.Body != nil {
+		io.Copy(buf, r.Body)
+	}
 	request := buf.String()
 
 	args := m.Called(r.URL.String(), request)
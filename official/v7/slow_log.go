@@ -0,0 +1,146 @@
+package v7
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// SlowLogConfig configures the slow search log. A search is slow when its
+// latency, including retries, exceeds Latency, or when the time elasticsearch
+// took to run it exceeds Took. A zero threshold is disabled.
+//
+// Search, MultiSearch, Scroll and ScrollCursor.Next are checked, and so the
+// pages of SearchWithPointInTime, whether they fail or not. Each search of a
+// MultiSearch is checked with the latency of the whole request.
+//
+// Slow searches are logged as a warning by the logger in the context. When
+// there is none, Logger is used, or the global logger if Logger is nil.
+// OnSlowSearch, if set, is also called for every slow search.
+type SlowLogConfig struct {
+	Latency      time.Duration
+	Took         time.Duration
+	Logger       *zerolog.Logger
+	OnSlowSearch func(context.Context, SlowSearch)
+}
+
+// SlowSearch describes a slow search. Query holds the query built from the
+// Filter with its values redacted. Err is the error of a failed search.
+type SlowSearch struct {
+	Indexes []string
+	Query   string
+	Sort    []string
+	Size    int
+	Latency time.Duration
+	Took    time.Duration
+	Hits    int
+	Total   int
+	Err     error
+}
+
+// WithSlowLog enables the slow search log.
+func WithSlowLog(config SlowLogConfig) Option {
+	return func(o *clientOptions) error {
+		o.slowLog = config
+		return nil
+	}
+}
+
+func (c SlowLogConfig) enabled() bool {
+	return c.Latency > 0 || c.Took > 0
+}
+
+func (c SlowLogConfig) isSlow(latency, took time.Duration) bool {
+	return (c.Latency > 0 && latency > c.Latency) ||
+		(c.Took > 0 && took > c.Took)
+}
+
+func (c SlowLogConfig) logger(ctx context.Context) *zerolog.Logger {
+	logger := log.Ctx(ctx)
+	if logger.GetLevel() != zerolog.Disabled {
+		return logger
+	}
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return &log.Logger
+}
+
+func (c SlowLogConfig) checkSearch(
+	ctx context.Context,
+	config SearchConfig,
+	response SearchResponse,
+	latency time.Duration,
+	err error,
+) {
+	took := time.Duration(response.Took) * time.Millisecond
+	if !c.enabled() || !c.isSlow(latency, took) {
+		return
+	}
+
+	slowSearch := SlowSearch{
+		Indexes: config.Indexes,
+		Query:   getQueryShape(config.Filter),
+		Sort:    config.Sort.Strings(),
+		Size:    config.Size,
+		Latency: latency,
+		Took:    took,
+		Hits:    len(response.Hits),
+		Total:   response.Total,
+		Err:     err,
+	}
+
+	c.logger(ctx).Warn().
+		Strs("elastic_indexes", slowSearch.Indexes).
+		Str("elastic_query", truncate(slowSearch.Query, defaultLogQueryMaxLength)).
+		Strs("elastic_sort", slowSearch.Sort).
+		Int("elastic_size", slowSearch.Size).
+		Dur("elastic_latency", slowSearch.Latency).
+		Dur("elastic_took_internal", slowSearch.Took).
+		Int("elastic_hits", slowSearch.Hits).
+		Int("elastic_total", slowSearch.Total).
+		Err(slowSearch.Err).
+		Msg("Elastic slow search")
+
+	if c.OnSlowSearch != nil {
+		c.OnSlowSearch(ctx, slowSearch)
+	}
+}
+
+// checkMultiSearch checks each search of a MultiSearch, using the @err of
+// the whole request when it failed.
+func (c SlowLogConfig) checkMultiSearch(
+	ctx context.Context,
+	configs []SearchConfig,
+	response MultiSearchResponse,
+	latency time.Duration,
+	err error,
+) {
+	if !c.enabled() {
+		return
+	}
+
+	for i, config := range configs {
+		var searchResponse SearchResponse
+		searchErr := err
+		if err == nil && i < len(response.Responses) {
+			searchResponse = response.Responses[i]
+			searchErr = response.Errors[i]
+		}
+		c.checkSearch(ctx, config, searchResponse, latency, searchErr)
+	}
+}
+
+// getQueryShape returns the query built from the @filter with its values
+// redacted.
+func getQueryShape(filter Filter) string {
+	query, err := buildElasticBoolQuery(filter)
+	if err != nil {
+		return err.Error()
+	}
+	return marshalQuery(querybuilders.Redact(query))
+}
@@ -0,0 +1,219 @@
+package v7
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Search_SlowLog(t *testing.T) {
+	t.Parallel()
+
+	type mustFilter struct {
+		Name []string
+	}
+
+	tests := []struct {
+		name               string
+		took               string
+		expectedSlowSearch *SlowSearch
+	}{
+		{
+			name: "slow search",
+			took: "10",
+			expectedSlowSearch: &SlowSearch{
+				Indexes: []string{"index1"},
+				Query:   `{"terms":{"Name":["?"]}}`,
+				Sort:    []string{"Name:asc"},
+				Size:    1,
+				Took:    10 * time.Millisecond,
+				Hits:    1,
+				Total:   3,
+			},
+		},
+		{
+			name: "fast search",
+			took: "1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport := new(mockTransport)
+			transport.On(
+				"RoundTrip",
+				"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=1&sort=Name%3Aasc&track_total_hits=false",
+				`{"query":{"terms":{"Name":["John"]}}}`,
+			).Once().Return(
+				`{"took":`+test.took+`,"hits":{"total":{"value":3},"hits":[{"_index":"index1","_id":"id-1"}]}}`,
+				200,
+				nil,
+			)
+
+			var buf bytes.Buffer
+			logger := zerolog.New(&buf)
+			var slowSearches []SlowSearch
+
+			client := newSlowLogClientTest(transport, SlowLogConfig{
+				Took:   5 * time.Millisecond,
+				Logger: &logger,
+				OnSlowSearch: func(_ context.Context, slowSearch SlowSearch) {
+					slowSearch.Latency = 0
+					slowSearches = append(slowSearches, slowSearch)
+				},
+			})
+
+			_, err := client.Search(context.Background(), SearchConfig{
+				Indexes: []string{"index1"},
+				Size:    1,
+				Filter:  Filter{Must: mustFilter{Name: []string{"John"}}},
+				Sort:    Sorters{Sorters: []Sorter{{Field: "Name", Ascending: true}}},
+			})
+			require.NoError(t, err)
+
+			if test.expectedSlowSearch == nil {
+				assert.Empty(t, slowSearches)
+				assert.Zero(t, buf.Len())
+				return
+			}
+
+			assert.Equal(t, []SlowSearch{*test.expectedSlowSearch}, slowSearches)
+
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+			assert.Equal(t, "warn", fields["level"])
+			assert.Equal(t, "Elastic slow search", fields["message"])
+			assert.Equal(t, test.expectedSlowSearch.Query, fields["elastic_query"])
+			assert.Equal(t, float64(3), fields["elastic_total"])
+		})
+	}
+}
+
+func Test_SlowLog_failedSearch(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&size=0&track_total_hits=false",
+		`{"query":{"match_all":{}}}`,
+	).Once().Return(
+		`{"error":{"type":"search_phase_execution_exception","reason":"all shards failed"},"status":400}`,
+		400,
+		nil,
+	)
+
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	var slowSearches []SlowSearch
+
+	client := newSlowLogClientTest(transport, SlowLogConfig{
+		Latency: time.Nanosecond,
+		Logger:  &logger,
+		OnSlowSearch: func(_ context.Context, slowSearch SlowSearch) {
+			slowSearches = append(slowSearches, slowSearch)
+		},
+	})
+
+	_, err := client.Search(context.Background(), SearchConfig{Indexes: []string{"index1"}})
+	require.Error(t, err)
+
+	require.Len(t, slowSearches, 1)
+	assert.Equal(t, err, slowSearches[0].Err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+	assert.Equal(t, "Elastic slow search", fields["message"])
+	assert.Equal(t, err.Error(), fields["error"])
+}
+
+func Test_SlowLog_MultiSearch(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/_msearch",
+		`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index1"]}`+"\n"+
+			`{"query":{"match_all":{}},"size":0,"track_total_hits":false}`+"\n"+
+			`{"allow_no_indices":false,"ignore_unavailable":false,"index":["index2"]}`+"\n"+
+			`{"query":{"match_all":{}},"size":0,"track_total_hits":false}`+"\n",
+	).Once().Return(
+		`{"took":10,"responses":[{"took":10,"hits":{"hits":[]},"status":200},{"took":1,"hits":{"hits":[]},"status":200}]}`,
+		200,
+		nil,
+	)
+
+	var indexes [][]string
+	client := newSlowLogClientTest(transport, SlowLogConfig{
+		Took: 5 * time.Millisecond,
+		OnSlowSearch: func(_ context.Context, slowSearch SlowSearch) {
+			indexes = append(indexes, slowSearch.Indexes)
+		},
+	})
+
+	_, err := client.MultiSearch(context.Background(), []SearchConfig{
+		{Indexes: []string{"index1"}},
+		{Indexes: []string{"index2"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"index1"}}, indexes)
+}
+
+func Test_SlowLog_Scroll(t *testing.T) {
+	t.Parallel()
+
+	transport := new(mockTransport)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/index1/_search?allow_no_indices=false&ignore_unavailable=false&scroll=60000ms&size=1&track_total_hits=false",
+		`{"query":{"match_all":{}}}`,
+	).Once().Return(
+		`{"_scroll_id":"scroll-1","took":10,"hits":{"hits":[{"_id":"id-1"}]}}`,
+		200,
+		nil,
+	)
+	transport.On(
+		"RoundTrip",
+		"http://localhost:9200/_search/scroll",
+		`{"scroll":"60000ms","scroll_id":"scroll-1"}`,
+	).Once().Return(
+		`{"_scroll_id":"scroll-1","took":1,"hits":{"hits":[{"_id":"id-2"}]}}`,
+		200,
+		nil,
+	)
+
+	var tooks []time.Duration
+	client := newSlowLogClientTest(transport, SlowLogConfig{
+		Took: 5 * time.Millisecond,
+		OnSlowSearch: func(_ context.Context, slowSearch SlowSearch) {
+			assert.Positive(t, slowSearch.Latency)
+			tooks = append(tooks, slowSearch.Took)
+		},
+	})
+
+	cursor, err := client.Scroll(context.Background(), SearchConfig{Indexes: []string{"index1"}, Size: 1}, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, tooks)
+
+	for i := 0; i < 2; i++ {
+		_, err = cursor.Next(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []time.Duration{10 * time.Millisecond}, tooks)
+}
+
+func newSlowLogClientTest(transport *mockTransport, config SlowLogConfig) Client {
+	return &instrumentedClient{
+		next:            mustNewClientTest(transport),
+		instrumentation: instrumentation{slowLog: config},
+	}
+}
@@ -0,0 +1,158 @@
+package v7
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+)
+
+const defaultTaskPollInterval = time.Second
+
+// TaskResponse represents the response for GetTask method.
+//
+// Status holds the progress of a running task. When the task is Completed,
+// Response holds its result, or Error holds why it failed.
+type TaskResponse struct {
+	ID        string
+	Completed bool
+	Status    ByQueryResponse
+	Response  ByQueryResponse
+	Error     *TaskError
+}
+
+// TaskError represents the error returned by elasticsearch for a failed
+// task.
+type TaskError struct {
+	Type   string
+	Reason string
+}
+
+func (e TaskError) Error() string {
+	return e.Type + ": " + e.Reason
+}
+
+type envelopeTask struct {
+	Completed bool `json:"completed"`
+	Task      struct {
+		Node   string          `json:"node"`
+		ID     int             `json:"id"`
+		Status envelopeByQuery `json:"status"`
+	} `json:"task"`
+	Response *envelopeByQuery    `json:"response"`
+	Error    *envelopeErrorCause `json:"error"`
+}
+
+// GetTask returns the current state of the task identified by @taskID, such
+// as the TaskID returned by an Async DeleteByQuery or UpdateByQuery.
+func (c *esClient) GetTask(ctx context.Context, taskID string) (TaskResponse, error) {
+	const op = errors.Op("v7.Client.GetTask")
+
+	response, err := c.doGetTask(ctx, taskID)
+	if err != nil {
+		return TaskResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseTaskResponse(response, taskID)
+	if err != nil {
+		return TaskResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doGetTask(ctx context.Context, taskID string) (*esapi.Response, error) {
+	const op = errors.Op("doGetTask")
+
+	if taskID == "" {
+		return nil, errors.E(op, requiredFieldError("taskID"), ErrCodeBadRequest)
+	}
+
+	response, err := c.client.Tasks.Get(
+		taskID,
+		c.client.Tasks.Get.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
+
+func parseTaskResponse(response *esapi.Response, taskID string) (TaskResponse, error) {
+	const op = errors.Op("parseTaskResponse")
+
+	var r envelopeTask
+	err := json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return TaskResponse{}, errors.E(op, err)
+	}
+
+	taskResponse := TaskResponse{
+		ID:        taskID,
+		Completed: r.Completed,
+		Status:    r.Task.Status.toResponse(),
+	}
+	if r.Response != nil {
+		taskResponse.Response = r.Response.toResponse()
+	}
+	if r.Error != nil {
+		taskResponse.Error = &TaskError{
+			Type:   r.Error.Type,
+			Reason: r.Error.Reason,
+		}
+	}
+
+	return taskResponse, nil
+}
+
+// WaitForTask polls the task identified by @taskID every @interval until it
+// completes or the @ctx is done. If @interval is not positive, it polls every
+// second. If the task fails, its TaskError is returned.
+func WaitForTask(
+	ctx context.Context,
+	client Client,
+	taskID string,
+	interval time.Duration,
+) (TaskResponse, error) {
+	const op = errors.Op("v7.WaitForTask")
+
+	if interval <= 0 {
+		interval = defaultTaskPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		response, err := client.GetTask(ctx, taskID)
+		if err != nil {
+			return TaskResponse{}, errors.E(op, err)
+		}
+
+		if response.Completed {
+			if response.Error != nil {
+				return response, errors.E(op, *response.Error, ErrCodeBadGateway)
+			}
+			return response, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return response, errors.E(op, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package v7
+
+import "context"
+
+// Tracer starts the spans around every Client operation. Its shape follows
+// OpenTelemetry's trace.Tracer, so an adapter only needs to convert the
+// Attributes.
+type Tracer interface {
+	// Start starts a span named @spanName as a child of the span in @ctx,
+	// if any, and returns a context holding the new span.
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span represents a single operation traced by a Tracer.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	// IsRecording returns false when the span is not sampled.
+	IsRecording() bool
+	End()
+}
+
+// Attribute represents a key and value pair added to a Span. Value is a
+// string, an int or an int64.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// WithTracer makes the Client start a span with the @tracer around every
+// operation. The span is named after the operation, such as
+// elasticsearch.Search, and holds the indexes, the query truncated to 1000
+// runes, the status code, the took and the error of the operation.
+func WithTracer(tracer Tracer) Option {
+	return func(o *clientOptions) error {
+		o.tracer = tracer
+		return nil
+	}
+}
+
+// WithRedactedStatement makes the spans hold the query with its values
+// replaced by querybuilders.RedactedValue, as LogConfig.RedactQuery does for
+// the logs. It should be used when the spans are exported to a backend that
+// must not store the searched values.
+func WithRedactedStatement() Option {
+	return func(o *clientOptions) error {
+		o.redactSpans = true
+		return nil
+	}
+}
+
+// WithFullQueryTracing makes the spans of sampled operations hold the full
+// query instead of a truncated one.
+func WithFullQueryTracing() Option {
+	return func(o *clientOptions) error {
+		o.fullQuery = true
+		return nil
+	}
+}
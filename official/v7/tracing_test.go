@@ -0,0 +1,146 @@
+package v7
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type parentKey struct{}
+
+type recordedTracer struct {
+	mu        sync.Mutex
+	recording bool
+	spans     []*recordedSpan
+}
+
+func (t *recordedTracer) Start(ctx context.Context, spanName string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordedSpan{
+		name:      spanName,
+		parent:    ctx.Value(parentKey{}),
+		recording: t.recording,
+	}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type recordedSpan struct {
+	name       string
+	parent     interface{}
+	recording  bool
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttributes(attributes ...Attribute) {
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	for _, attribute := range attributes {
+		s.attributes[attribute.Key] = attribute.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) IsRecording() bool     { return s.recording }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+func Test_WithTracer(t *testing.T) {
+	t.Parallel()
+
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		body := `{}`
+		if r.URL.Path == "/index1/_search" {
+			body = `{"took":7,"hits":{"total":{"value":0},"hits":[]}}`
+		}
+		response := &http.Response{
+			StatusCode: 200,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		response.Header.Add("X-Elastic-Product", "Elasticsearch")
+		return response, nil
+	})
+
+	type mustFilter struct {
+		Name []string
+	}
+	config := SearchConfig{
+		Indexes: []string{"index1"},
+		Filter: Filter{
+			Must: mustFilter{Name: []string{strings.Repeat("a", tracingQueryMaxLength)}},
+		},
+	}
+	query, err := getQuery(context.Background(), config)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		recording     bool
+		options       []Option
+		expectedQuery string
+	}{
+		{
+			name:          "truncated query",
+			recording:     true,
+			expectedQuery: truncate(query, tracingQueryMaxLength),
+		},
+		{
+			name:          "full query when sampled",
+			recording:     true,
+			options:       []Option{WithFullQueryTracing()},
+			expectedQuery: query,
+		},
+		{
+			name:          "truncated query when not sampled",
+			recording:     false,
+			options:       []Option{WithFullQueryTracing()},
+			expectedQuery: truncate(query, tracingQueryMaxLength),
+		},
+		{
+			name:          "redacted query",
+			recording:     true,
+			options:       []Option{WithRedactedStatement()},
+			expectedQuery: `{"query":{"terms":{"Name":["?"]}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tracer := &recordedTracer{recording: test.recording}
+			options := append([]Option{WithTransport(transport), WithTracer(tracer)}, test.options...)
+			client, err := NewClientWithOptions([]string{"http://localhost:9200"}, options...)
+			require.NoError(t, err)
+
+			ctx := context.WithValue(context.Background(), parentKey{}, "parent")
+			_, err = client.Search(ctx, config)
+			require.NoError(t, err)
+
+			require.Len(t, tracer.spans, 1)
+			span := tracer.spans[0]
+			assert.Equal(t, "elasticsearch.Search", span.name)
+			assert.Equal(t, "parent", span.parent)
+			assert.True(t, span.ended)
+			assert.NoError(t, span.err)
+			assert.Equal(t, map[string]interface{}{
+				"db.system":                "elasticsearch",
+				"db.operation":             "Search",
+				"db.elasticsearch.indexes": "index1",
+				"db.statement":             test.expectedQuery,
+				"http.status_code":         200,
+				"db.elasticsearch.took_ms": int64(7),
+			}, span.attributes)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package v7
+
+import (
+	"context"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+
+	"github.com/arquivei/elasticutil/official/v7/querybuilders"
+)
+
+// UpdateByQueryConfig hold all information to UpdateByQuery method.
+//
+// Script is applied to every document that matches the Filter. Without a
+// Script, the documents are reindexed as they are, which picks up mapping
+// changes. The other fields work as in DeleteByQueryConfig.
+type UpdateByQueryConfig struct {
+	Indexes           []string
+	Filter            Filter
+	Script            *querybuilders.Script
+	Conflicts         Conflicts
+	Slices            int
+	RequestsPerSecond *float64
+	MatchAll          bool
+	Refresh           bool
+	Async             bool
+	IgnoreUnavailable bool
+	AllowNoIndices    bool
+}
+
+// UpdateByQuery updates all documents that match the @config's Filter. If
+// elasticsearch stops the operation, such as on a version conflict with
+// ConflictsAbort, the error holds a ByQueryError.
+func (c *esClient) UpdateByQuery(ctx context.Context, config UpdateByQueryConfig) (ByQueryResponse, error) {
+	const op = errors.Op("v7.Client.UpdateByQuery")
+
+	enrichLogWithIndexes(ctx, config.Indexes)
+
+	response, err := c.doUpdateByQuery(ctx, config)
+	if err != nil {
+		return ByQueryResponse{}, errors.E(op, err)
+	}
+	defer response.Body.Close()
+
+	parsedResponse, err := parseByQueryResponse(response)
+	if err != nil {
+		return ByQueryResponse{}, errors.E(op, err, ErrCodeUnexpectedResponse)
+	}
+
+	return parsedResponse, nil
+}
+
+func (c *esClient) doUpdateByQuery(ctx context.Context, config UpdateByQueryConfig) (*esapi.Response, error) {
+	const op = errors.Op("doUpdateByQuery")
+
+	if len(config.Indexes) == 0 {
+		return nil, errors.E(op, requiredFieldError("Indexes"), ErrCodeBadRequest)
+	}
+
+	body, err := getByQueryBody(config.Filter, config.MatchAll, config.Script)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadRequest)
+	}
+
+	options := []func(*esapi.UpdateByQueryRequest){
+		c.client.UpdateByQuery.WithContext(ctx),
+		c.client.UpdateByQuery.WithBody(body),
+		c.client.UpdateByQuery.WithIgnoreUnavailable(config.IgnoreUnavailable),
+		c.client.UpdateByQuery.WithAllowNoIndices(config.AllowNoIndices),
+	}
+	if config.Conflicts != "" {
+		options = append(options, c.client.UpdateByQuery.WithConflicts(string(config.Conflicts)))
+	}
+	if config.Slices != 0 {
+		options = append(options, c.client.UpdateByQuery.WithSlices(slicesParam(config.Slices)))
+	}
+	if config.Refresh {
+		options = append(options, c.client.UpdateByQuery.WithRefresh(true))
+	}
+	if config.Async {
+		options = append(options, c.client.UpdateByQuery.WithWaitForCompletion(false))
+	}
+
+	response, err := c.byQueryAPI(config.RequestsPerSecond).UpdateByQuery(config.Indexes, options...)
+	if err != nil {
+		return nil, errors.E(op, err, ErrCodeBadGateway)
+	}
+
+	err = checkByQueryErrorFromResponse(response)
+	if err != nil {
+		response.Body.Close()
+		if errors.GetCode(err) == errors.CodeEmpty {
+			err = errors.E(err, ErrCodeBadGateway)
+		}
+		return nil, errors.E(op, err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,325 @@
+package v7test
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const defaultTermsSize = 10
+
+// runAggregations computes the @aggs, given as the decoded "aggs" of a
+// search body, over the matched @documents.
+func runAggregations(aggs map[string]interface{}, documents []*fakeDocument) (map[string]interface{}, error) {
+	results := make(map[string]interface{}, len(aggs))
+	for name, agg := range aggs {
+		definition, isObject := agg.(map[string]interface{})
+		if !isObject {
+			return nil, parsingError("aggregation [" + name + "] must be an object")
+		}
+
+		result, err := runAggregation(name, definition, documents)
+		if err != nil {
+			return nil, err
+		}
+		results[name] = result
+	}
+	return results, nil
+}
+
+func runAggregation(name string, definition map[string]interface{}, documents []*fakeDocument) (map[string]interface{}, error) {
+	subAggs, _ := definition["aggs"].(map[string]interface{})
+	if subAggs == nil {
+		subAggs, _ = definition["aggregations"].(map[string]interface{})
+	}
+
+	for kind, params := range definition {
+		options, _ := params.(map[string]interface{})
+		field, _ := options["field"].(string)
+
+		switch kind {
+		case "aggs", "aggregations", "meta":
+			continue
+		case "min", "max", "sum", "avg", "value_count":
+			return map[string]interface{}{"value": metric(kind, field, documents)}, nil
+		case "terms":
+			return termsAggregation(options, field, subAggs, documents)
+		case "date_histogram":
+			return dateHistogramAggregation(options, field, subAggs, documents)
+		default:
+			return nil, notSupported("aggregation type [" + kind + "] of [" + name + "]")
+		}
+	}
+	return nil, parsingError("missing definition for aggregation [" + name + "]")
+}
+
+// metric computes a metric aggregation over the numeric values of the
+// @field. Dates are used as epoch milliseconds. As in elasticsearch, min,
+// max and avg are null when there are no values.
+func metric(kind, field string, documents []*fakeDocument) interface{} {
+	var values []float64
+	count := 0
+	for _, document := range documents {
+		for _, value := range lookup(document.value, field) {
+			count++
+			if n, isNumber := toNumber(value); isNumber {
+				values = append(values, n)
+			} else if t, isTime := toTime(value); isTime {
+				values = append(values, float64(t.UnixMilli()))
+			}
+		}
+	}
+
+	if kind == "value_count" {
+		return count
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	if kind == "sum" {
+		return sum
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	result := values[0]
+	for _, value := range values[1:] {
+		switch kind {
+		case "min":
+			result = min(result, value)
+		case "max":
+			result = max(result, value)
+		}
+	}
+	if kind == "avg" {
+		result = sum / float64(len(values))
+	}
+	return result
+}
+
+type bucket struct {
+	key         interface{}
+	keyAsString string
+	sortKey     interface{}
+	documents   []*fakeDocument
+}
+
+func (b *bucket) source(subAggs map[string]interface{}) (map[string]interface{}, error) {
+	source, err := runAggregations(subAggs, b.documents)
+	if err != nil {
+		return nil, err
+	}
+	source["key"] = b.key
+	source["doc_count"] = len(b.documents)
+	if b.keyAsString != "" {
+		source["key_as_string"] = b.keyAsString
+	}
+	return source, nil
+}
+
+// termsAggregation groups the @documents by each distinct value of the
+// @field, sorted by document count and then by key.
+func termsAggregation(
+	options map[string]interface{},
+	field string,
+	subAggs map[string]interface{},
+	documents []*fakeDocument,
+) (map[string]interface{}, error) {
+	size := defaultTermsSize
+	if value, ok := numberValue("", options["size"]); ok {
+		size = value
+	}
+	minDocCount := 1
+	if value, ok := numberValue("", options["min_doc_count"]); ok {
+		minDocCount = value
+	}
+
+	buckets := make(map[string]*bucket)
+	for _, document := range documents {
+		seen := make(map[string]bool)
+		for _, value := range lookup(document.value, field) {
+			b := termsBucket(value)
+			id := fmt.Sprint(b.key)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			if current, ok := buckets[id]; ok {
+				b = current
+			}
+			b.documents = append(b.documents, document)
+			buckets[id] = b
+		}
+	}
+
+	sorted := make([]*bucket, 0, len(buckets))
+	for _, b := range buckets {
+		if len(b.documents) >= minDocCount {
+			sorted = append(sorted, b)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].documents) != len(sorted[j].documents) {
+			return len(sorted[i].documents) > len(sorted[j].documents)
+		}
+		c, _ := compareValues(sorted[i].sortKey, sorted[j].sortKey)
+		return c < 0
+	})
+
+	others := 0
+	if len(sorted) > size {
+		for _, b := range sorted[size:] {
+			others += len(b.documents)
+		}
+		sorted = sorted[:size]
+	}
+
+	sources, err := bucketsSource(sorted, subAggs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"doc_count_error_upper_bound": 0,
+		"sum_other_doc_count":         others,
+		"buckets":                     sources,
+	}, nil
+}
+
+// termsBucket returns the bucket of a terms value. Booleans are keyed as 1
+// and 0, as elasticsearch does.
+func termsBucket(value interface{}) *bucket {
+	switch v := value.(type) {
+	case bool:
+		b := &bucket{key: 0, keyAsString: "false", sortKey: 0.0}
+		if v {
+			b.key, b.keyAsString, b.sortKey = 1, "true", 1.0
+		}
+		return b
+	case string:
+		return &bucket{key: v, sortKey: v}
+	}
+	n, _ := toNumber(value)
+	return &bucket{key: value, sortKey: n}
+}
+
+// dateHistogramAggregation groups the @documents by the calendar interval
+// of the date @field, filling the empty intervals between the first and
+// the last buckets unless min_doc_count is set.
+// nolint: cyclop
+func dateHistogramAggregation(
+	options map[string]interface{},
+	field string,
+	subAggs map[string]interface{},
+	documents []*fakeDocument,
+) (map[string]interface{}, error) {
+	interval, _ := options["calendar_interval"].(string)
+	if interval == "" {
+		interval, _ = options["interval"].(string)
+	}
+	truncate, next, ok := calendarInterval(interval)
+	if !ok {
+		return nil, notSupported("date_histogram interval [" + interval + "]")
+	}
+	minDocCount := 0
+	if value, ok := numberValue("", options["min_doc_count"]); ok {
+		minDocCount = value
+	}
+
+	buckets := make(map[int64]*bucket)
+	for _, document := range documents {
+		seen := make(map[int64]bool)
+		for _, value := range lookup(document.value, field) {
+			t, isTime := toTime(value)
+			if !isTime {
+				continue
+			}
+			start := truncate(t.UTC())
+			key := start.UnixMilli()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			b, ok := buckets[key]
+			if !ok {
+				b = &bucket{key: key, keyAsString: start.Format("2006-01-02T15:04:05.000Z")}
+				buckets[key] = b
+			}
+			b.documents = append(b.documents, document)
+		}
+	}
+
+	keys := make([]int64, 0, len(buckets))
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var sorted []*bucket
+	if len(keys) > 0 {
+		last := time.UnixMilli(keys[len(keys)-1]).UTC()
+		for t := time.UnixMilli(keys[0]).UTC(); !t.After(last); t = next(t) {
+			b, ok := buckets[t.UnixMilli()]
+			if !ok {
+				b = &bucket{key: t.UnixMilli(), keyAsString: t.Format("2006-01-02T15:04:05.000Z")}
+			}
+			if len(b.documents) >= minDocCount {
+				sorted = append(sorted, b)
+			}
+		}
+	}
+
+	sources, err := bucketsSource(sorted, subAggs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"buckets": sources}, nil
+}
+
+// calendarInterval returns how to truncate a date to the start of the
+// @interval and how to get the start of the next one.
+func calendarInterval(interval string) (func(time.Time) time.Time, func(time.Time) time.Time, bool) {
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	switch interval {
+	case "day", "1d":
+		return day, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }, true
+	case "week", "1w":
+		return func(t time.Time) time.Time {
+			weekday := (int(t.Weekday()) + 6) % 7
+			return day(t).AddDate(0, 0, -weekday)
+		}, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }, true
+	case "month", "1M":
+		return func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }, true
+	case "quarter", "1q":
+		return func(t time.Time) time.Time {
+			month := time.Month((int(t.Month())-1)/3*3 + 1)
+			return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+		}, func(t time.Time) time.Time { return t.AddDate(0, 3, 0) }, true
+	case "year", "1y":
+		return func(t time.Time) time.Time {
+			return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		}, func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }, true
+	}
+	return nil, nil, false
+}
+
+func bucketsSource(buckets []*bucket, subAggs map[string]interface{}) ([]interface{}, error) {
+	sources := make([]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		source, err := b.source(subAggs)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
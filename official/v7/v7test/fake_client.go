@@ -0,0 +1,516 @@
+// Package v7test provides helpers to test code that uses the v7.Client.
+package v7test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	v7 "github.com/arquivei/elasticutil/official/v7"
+)
+
+const fakeURL = "http://fake-elasticsearch:9200"
+
+// FakeClient is a v7.Client backed by an in-memory elasticsearch. Tests load
+// JSON documents per index and every Client method runs against them, so a
+// test can check that a Filter matches the right documents instead of
+// mocking the response.
+//
+// The requests built by the Client are evaluated by the fake, which
+// understands the term, terms, range, exists, bool, nested, match_all and
+// multi_match queries, the sort, size, from, search_after and _source of a
+// search, and the min, max, sum, avg, value_count, terms and date_histogram
+// aggregations. Documents are not analyzed: term queries compare the values
+// as they are and multi_match compares lowercased words. Writes are visible
+// right away and scripts are not supported.
+type FakeClient struct {
+	v7.Client
+	es *fakeElasticsearch
+}
+
+// NewFakeClient returns a FakeClient without indexes. The @options are
+// passed to v7.NewClientWithOptions, so the fake can be combined with
+// options such as v7.WithMetrics. It panics if the @options are invalid.
+func NewFakeClient(options ...v7.Option) *FakeClient {
+	es := newFakeElasticsearch()
+	options = append(options, v7.WithTransport(es))
+
+	return &FakeClient{
+		Client: v7.MustNewClientWithOptions([]string{fakeURL}, options...),
+		es:     es,
+	}
+}
+
+// CreateIndex creates an empty @index. Indexes are also created by the
+// first document added to them.
+func (c *FakeClient) CreateIndex(index string) {
+	c.es.mu.Lock()
+	defer c.es.mu.Unlock()
+
+	c.es.getOrCreateIndex(index)
+}
+
+// AddDocument stores the @document in the @index with the @id, replacing
+// the current one, if any. The @document is encoded as JSON, unless it is
+// already a []byte or a json.RawMessage.
+func (c *FakeClient) AddDocument(index, id string, document interface{}) error {
+	source, err := encodeDocument(document)
+	if err != nil {
+		return err
+	}
+
+	c.es.mu.Lock()
+	defer c.es.mu.Unlock()
+
+	_, err = c.es.indexDocument(writeRequest{Index: index, ID: id}, source)
+	return err
+}
+
+// LoadDocuments stores the documents read from @r in the @index. The
+// content must be a JSON object mapping each document ID to its document,
+// and the documents are stored in the order they are read.
+func (c *FakeClient) LoadDocuments(index string, r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return errUnexpectedDocuments
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		id, _ := token.(string)
+
+		var document json.RawMessage
+		err = decoder.Decode(&document)
+		if err != nil {
+			return err
+		}
+
+		err = c.AddDocument(index, id, document)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+	return err
+}
+
+// LoadFile works like LoadDocuments, reading the documents from the file
+// named @filename.
+func (c *FakeClient) LoadFile(index, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return c.LoadDocuments(index, file)
+}
+
+// Document returns the current source of the document with the @id in the
+// @index, so tests can check the effects of the write methods.
+func (c *FakeClient) Document(index, id string) (json.RawMessage, bool) {
+	c.es.mu.Lock()
+	defer c.es.mu.Unlock()
+
+	idx, ok := c.es.indexes[index]
+	if !ok {
+		return nil, false
+	}
+	document, ok := idx.documents[id]
+	if !ok {
+		return nil, false
+	}
+	return document.source, true
+}
+
+// Indexes returns the names of the existing indexes, sorted.
+func (c *FakeClient) Indexes() []string {
+	c.es.mu.Lock()
+	defer c.es.mu.Unlock()
+
+	return sortedKeys(c.es.indexes)
+}
+
+// fakeElasticsearch is the http.RoundTripper that answers the requests of
+// the Client as elasticsearch would.
+type fakeElasticsearch struct {
+	mu        sync.Mutex
+	indexes   map[string]*fakeIndex
+	pits      map[string]map[string]*fakeIndex
+	scrolls   map[string]*fakeScroll
+	tasks     map[string]interface{}
+	positions int
+	lastID    int
+}
+
+// fakeIndex holds the documents of an index. A fakeDocument is never
+// changed, a write replaces it, so a copy of the documents map is a
+// snapshot of the index.
+type fakeIndex struct {
+	name      string
+	documents map[string]*fakeDocument
+	seqNo     int
+}
+
+type fakeDocument struct {
+	id       string
+	source   json.RawMessage
+	value    interface{}
+	version  int
+	seqNo    int
+	position int
+}
+
+func newFakeElasticsearch() *fakeElasticsearch {
+	return &fakeElasticsearch{
+		indexes: make(map[string]*fakeIndex),
+		pits:    make(map[string]map[string]*fakeIndex),
+		scrolls: make(map[string]*fakeScroll),
+		tasks:   make(map[string]interface{}),
+	}
+}
+
+func (f *fakeElasticsearch) getOrCreateIndex(name string) *fakeIndex {
+	index, ok := f.indexes[name]
+	if !ok {
+		index = &fakeIndex{
+			name:      name,
+			documents: make(map[string]*fakeDocument),
+		}
+		f.indexes[name] = index
+	}
+	return index
+}
+
+// sortedDocuments returns the documents of the @index in the order they
+// were created.
+func (i *fakeIndex) sortedDocuments() []*fakeDocument {
+	documents := make([]*fakeDocument, 0, len(i.documents))
+	for _, document := range i.documents {
+		documents = append(documents, document)
+	}
+	sort.Slice(documents, func(a, b int) bool {
+		return documents[a].position < documents[b].position
+	})
+	return documents
+}
+
+func (i *fakeIndex) snapshot() *fakeIndex {
+	documents := make(map[string]*fakeDocument, len(i.documents))
+	for id, document := range i.documents {
+		documents[id] = document
+	}
+	return &fakeIndex{
+		name:      i.name,
+		documents: documents,
+		seqNo:     i.seqNo,
+	}
+}
+
+// fakeRequest is a request received by the fake, with its path split in
+// segments and its body already read.
+type fakeRequest struct {
+	method   string
+	segments []string
+	params   url.Values
+	body     []byte
+}
+
+type fakeResponse struct {
+	status int
+	body   interface{}
+}
+
+func success(body interface{}) fakeResponse {
+	return fakeResponse{status: http.StatusOK, body: body}
+}
+
+func failure(err error) fakeResponse {
+	e, isFakeError := err.(*fakeError)
+	if !isFakeError {
+		e = badRequest("parse_exception", err.Error())
+	}
+	return fakeResponse{status: e.status, body: map[string]interface{}{
+		"error":  e.source(),
+		"status": e.status,
+	}}
+}
+
+func (f *fakeElasticsearch) RoundTrip(r *http.Request) (*http.Response, error) {
+	request, err := readRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	response := f.route(request)
+	f.mu.Unlock()
+
+	body, err := json.Marshal(response.body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json; charset=UTF-8")
+	header.Set("X-Elastic-Product", "Elasticsearch")
+
+	return &http.Response{
+		StatusCode:    response.status,
+		Status:        http.StatusText(response.status),
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}, nil
+}
+
+func readRequest(r *http.Request) (fakeRequest, error) {
+	request := fakeRequest{
+		method:   r.Method,
+		segments: splitPath(r.URL.Path),
+		params:   r.URL.Query(),
+	}
+	if r.Body == nil {
+		return request, nil
+	}
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fakeRequest{}, err
+	}
+	request.body, err = decodeBody(r.Header, body)
+	return request, err
+}
+
+// decodeBody returns the @body of a request, uncompressing it if the
+// @header says it is gzipped, as the Client does with
+// v7.WithCompression.
+func decodeBody(header http.Header, body []byte) ([]byte, error) {
+	if header.Get("Content-Encoding") != "gzip" {
+		return body, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// nolint: cyclop
+func (f *fakeElasticsearch) route(r fakeRequest) fakeResponse {
+	s := r.segments
+	switch {
+	case len(s) == 0:
+		return success(map[string]interface{}{
+			"name":         "fake",
+			"cluster_name": "fake",
+			"version":      map[string]interface{}{"number": "7.17.10", "build_flavor": "default"},
+			"tagline":      "You Know, for Search",
+		})
+	case len(s) == 2 && s[0] == "_search" && s[1] == "scroll":
+		if r.method == http.MethodDelete {
+			return f.clearScroll(r)
+		}
+		return f.scroll(r)
+	case s[len(s)-1] == "_search":
+		return f.search(r, indexesFromPath(s))
+	case s[len(s)-1] == "_msearch":
+		return f.multiSearch(r)
+	case s[len(s)-1] == "_count":
+		return f.count(r, indexesFromPath(s))
+	case s[len(s)-1] == "_mget":
+		return f.mget(r, indexFromPath(s))
+	case s[len(s)-1] == "_bulk":
+		return f.bulk(r, indexFromPath(s))
+	case len(s) == 1 && s[0] == "_pit":
+		return f.closePointInTime(r)
+	case len(s) == 2 && s[1] == "_pit":
+		return f.openPointInTime(r, indexesFromPath(s))
+	case len(s) == 2 && s[1] == "_delete_by_query":
+		return f.deleteByQuery(r, indexesFromPath(s))
+	case len(s) == 2 && s[1] == "_update_by_query":
+		return f.updateByQuery(r, indexesFromPath(s))
+	case len(s) == 2 && s[0] == "_tasks":
+		return f.getTask(s[1])
+	case len(s) == 2 && s[1] == "_doc" && r.method == http.MethodPost:
+		return f.index(r, s[0], "")
+	case len(s) == 3 && (s[1] == "_doc" || s[1] == "_create"):
+		switch r.method {
+		case http.MethodGet, http.MethodHead:
+			return f.get(r, s[0], s[2])
+		case http.MethodDelete:
+			return f.delete(r, s[0], s[2])
+		}
+		if s[1] == "_create" {
+			r.params.Set("op_type", "create")
+		}
+		return f.index(r, s[0], s[2])
+	case len(s) == 3 && s[1] == "_update":
+		return f.update(r, s[0], s[2])
+	case len(s) == 4 && s[1] == "_doc" && s[3] == "_update":
+		return f.update(r, s[0], s[2])
+	}
+
+	return failure(badRequest(
+		"illegal_argument_exception",
+		"request ["+r.method+" /"+strings.Join(s, "/")+"] is not supported by the fake",
+	))
+}
+
+func indexesFromPath(segments []string) []string {
+	if len(segments) < 2 {
+		return nil
+	}
+	return strings.Split(segments[0], ",")
+}
+
+func indexFromPath(segments []string) string {
+	if len(segments) < 2 {
+		return ""
+	}
+	return segments[0]
+}
+
+// resolveIndexes returns the indexes named by @names, which may have
+// wildcards, taken from @indexes. Missing indexes fail unless
+// ignore_unavailable is set, and matching no index fails unless
+// allow_no_indices is set, as elasticsearch does.
+func resolveIndexes(
+	indexes map[string]*fakeIndex,
+	names []string,
+	ignoreUnavailable bool,
+	allowNoIndices bool,
+) ([]*fakeIndex, error) {
+	available := sortedKeys(indexes)
+	if len(names) == 0 || (len(names) == 1 && names[0] == "_all") {
+		names = []string{"*"}
+	}
+
+	var resolved []*fakeIndex
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !strings.Contains(name, "*") {
+			index, ok := indexes[name]
+			if !ok {
+				if ignoreUnavailable {
+					continue
+				}
+				return nil, indexNotFound(name)
+			}
+			if !seen[name] {
+				seen[name] = true
+				resolved = append(resolved, index)
+			}
+			continue
+		}
+
+		for _, candidate := range available {
+			if wildcardMatch(name, candidate) && !seen[candidate] {
+				seen[candidate] = true
+				resolved = append(resolved, indexes[candidate])
+			}
+		}
+	}
+
+	if len(resolved) == 0 && !allowNoIndices {
+		return nil, indexNotFound(strings.Join(names, ","))
+	}
+
+	return resolved, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func boolParam(params url.Values, name string, defaultValue bool) bool {
+	value := params.Get(name)
+	if value == "" {
+		return defaultValue
+	}
+	return value == "true"
+}
+
+func encodeDocument(document interface{}) (json.RawMessage, error) {
+	switch d := document.(type) {
+	case json.RawMessage:
+		return d, nil
+	case []byte:
+		return d, nil
+	}
+	return json.Marshal(document)
+}
+
+// decode decodes @data keeping the numbers as json.Number, so they are
+// encoded back without losing precision.
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	value, err := decode(data)
+	if err != nil {
+		return nil, badRequest("parse_exception", "failed to parse request body: "+err.Error())
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, badRequest("parse_exception", "request body must be an object")
+	}
+	return object, nil
+}
+
+// ndjsonLines returns the non empty lines of a bulk or multi search body.
+func ndjsonLines(body []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,444 @@
+package v7test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/arquivei/foundationkit/ref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v7 "github.com/arquivei/elasticutil/official/v7"
+)
+
+const people = `{
+	"1": {"Name": "John", "Age": 16, "Sick": true, "CreatedAt": "2020-01-10T10:00:00Z",
+		"Bio": "Plays the guitar in a band", "Covid": [{"Symptom": "cough", "Date": "2020-03-01T00:00:00Z"}]},
+	"2": {"Name": "Mary", "Age": 25, "Sick": false, "CreatedAt": "2020-01-20T10:00:00Z",
+		"Bio": "Sings in a choir"},
+	"3": {"Name": "Paul", "Age": 30, "Sick": true, "CreatedAt": "2020-02-05T10:00:00Z",
+		"Bio": "Plays the bass", "Covid": [{"Symptom": "fever", "Date": "2020-04-01T00:00:00Z"}]},
+	"4": {"Name": "Rebecca", "Age": 41, "CreatedAt": "2020-03-15T10:00:00Z"}
+}`
+
+func newPeopleClient(t *testing.T) *FakeClient {
+	t.Helper()
+
+	client := NewFakeClient()
+	require.NoError(t, client.LoadDocuments("people", strings.NewReader(people)))
+	return client
+}
+
+func Test_FakeClient_Search(t *testing.T) {
+	t.Parallel()
+
+	type covidInfo struct {
+		Symptoms []string      `es:"Covid.Symptom"`
+		Date     *v7.TimeRange `es:"Covid.Date"`
+	}
+
+	type filterMust struct {
+		Names     []string                  `es:"Name"`
+		Ages      []uint64                  `es:"Age"`
+		Sick      *bool                     `es:"Sick"`
+		CreatedAt *v7.TimeRange             `es:"CreatedAt"`
+		AgeRange  *v7.IntRange              `es:"Age"`
+		Covid     v7.Nested                 `es:"Covid"`
+		Bio       v7.FullTextSearchMust     `es:"Bio"`
+		Any       v7.MultiMatchSearchShould `es:"Bio,Name"`
+	}
+
+	type filterExists struct {
+		HasSick *bool `es:"Sick"`
+	}
+
+	tests := []struct {
+		name          string
+		config        v7.SearchConfig
+		expectedIDs   []string
+		expectedTotal int
+		expectedError string
+	}{
+		{
+			name:          "match all",
+			config:        v7.SearchConfig{Indexes: []string{"people"}},
+			expectedIDs:   []string{"1", "2", "3", "4"},
+			expectedTotal: 4,
+		},
+		{
+			name: "terms",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter:  v7.Filter{Must: filterMust{Names: []string{"Mary", "Paul"}}},
+			},
+			expectedIDs:   []string{"2", "3"},
+			expectedTotal: 2,
+		},
+		{
+			name: "numeric terms and term",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter:  v7.Filter{Must: filterMust{Ages: []uint64{16, 30}, Sick: ref.Of(true)}},
+			},
+			expectedIDs:   []string{"1", "3"},
+			expectedTotal: 2,
+		},
+		{
+			name: "ranges",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter: v7.Filter{Must: filterMust{
+					AgeRange: &v7.IntRange{From: 20},
+					CreatedAt: &v7.TimeRange{
+						To: time.Date(2020, time.February, 5, 10, 0, 0, 0, time.UTC),
+					},
+				}},
+			},
+			expectedIDs:   []string{"2", "3"},
+			expectedTotal: 2,
+		},
+		{
+			name: "must not",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter:  v7.Filter{MustNot: filterMust{Names: []string{"John"}}},
+			},
+			expectedIDs:   []string{"2", "3", "4"},
+			expectedTotal: 3,
+		},
+		{
+			name: "exists and not exists",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter:  v7.Filter{Exists: filterExists{HasSick: ref.Of(false)}},
+			},
+			expectedIDs:   []string{"4"},
+			expectedTotal: 1,
+		},
+		{
+			name: "nested",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter: v7.Filter{Must: filterMust{Covid: v7.NewNested(covidInfo{
+					Symptoms: []string{"fever"},
+					Date:     &v7.TimeRange{From: time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC)},
+				})}},
+			},
+			expectedIDs:   []string{"3"},
+			expectedTotal: 1,
+		},
+		{
+			name: "nested clauses must match the same object",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter: v7.Filter{Must: filterMust{Covid: v7.NewNested(covidInfo{
+					Symptoms: []string{"cough"},
+					Date:     &v7.TimeRange{From: time.Date(2020, time.March, 15, 0, 0, 0, 0, time.UTC)},
+				})}},
+			},
+			expectedTotal: 0,
+		},
+		{
+			name: "phrase prefix",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter:  v7.Filter{Must: filterMust{Bio: v7.NewFullTextSearchMust([]string{"plays the gui"})}},
+			},
+			expectedIDs:   []string{"1"},
+			expectedTotal: 1,
+		},
+		{
+			name: "multi match",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Filter:  v7.Filter{Must: filterMust{Any: v7.NewMultiMatchSearchShould([]string{"choir", "rebecca"})}},
+			},
+			expectedIDs:   []string{"2", "4"},
+			expectedTotal: 2,
+		},
+		{
+			name: "sort and size",
+			config: v7.SearchConfig{
+				Indexes: []string{"people"},
+				Size:    2,
+				Sort:    v7.Sorters{Sorters: []v7.Sorter{{Field: "Age", Ascending: false}}},
+			},
+			expectedIDs:   []string{"4", "3"},
+			expectedTotal: 4,
+		},
+		{
+			name: "search after",
+			config: v7.SearchConfig{
+				Indexes:     []string{"people"},
+				Size:        2,
+				Sort:        v7.Sorters{Sorters: []v7.Sorter{{Field: "Age", Ascending: false}}},
+				SearchAfter: "[30]",
+			},
+			expectedIDs:   []string{"2", "1"},
+			expectedTotal: 4,
+		},
+		{
+			name: "search before",
+			config: v7.SearchConfig{
+				Indexes:      []string{"people"},
+				Size:         2,
+				Sort:         v7.Sorters{Sorters: []v7.Sorter{{Field: "Age", Ascending: false}}},
+				SearchBefore: "[25]",
+			},
+			expectedIDs:   []string{"4", "3"},
+			expectedTotal: 4,
+		},
+		{
+			name: "missing index",
+			config: v7.SearchConfig{
+				Indexes: []string{"animals"},
+			},
+			expectedError: "index_not_found_exception",
+		},
+		{
+			name: "missing index ignored",
+			config: v7.SearchConfig{
+				Indexes:           []string{"animals", "people"},
+				IgnoreUnavailable: true,
+				Filter:            v7.Filter{Must: filterMust{Names: []string{"John"}}},
+			},
+			expectedIDs:   []string{"1"},
+			expectedTotal: 1,
+		},
+	}
+
+	client := newPeopleClient(t)
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			if test.config.Size == 0 {
+				test.config.Size = 10
+			}
+			test.config.TrackTotalHits = true
+			response, err := client.Search(context.Background(), test.config)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedIDs, response.IDs)
+			assert.Equal(t, test.expectedTotal, response.Total)
+		})
+	}
+}
+
+func Test_FakeClient_Aggregations(t *testing.T) {
+	t.Parallel()
+
+	client := newPeopleClient(t)
+	response, err := client.Search(context.Background(), v7.SearchConfig{
+		Indexes: []string{"people"},
+		Aggregation: v7.RequestAggregation{
+			Metrics: []v7.RequestMetricAggregation{
+				{Name: "max_age", Type: "max", Field: "Age"},
+			},
+			Buckets: []v7.RequestBucketAggregation{
+				{
+					Name:  "by_sick",
+					Type:  "term",
+					Field: "Sick",
+					MetricsSubAgg: []v7.RequestMetricAggregation{
+						{Name: "min_age", Type: "min", Field: "Age"},
+					},
+				},
+				{Name: "by_month", Type: "monthlyhistogram", Field: "CreatedAt"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []v7.ResponseMetricAggregation{{Name: "max_age", Value: 41.0}},
+		response.Aggregations.MetricAggregations)
+
+	buckets := make(map[string][]v7.ResponseBucket)
+	for _, aggregation := range response.Aggregations.BucketAggregations {
+		buckets[aggregation.Name] = aggregation.Buckets
+	}
+	assert.Equal(t, []v7.ResponseBucket{
+		{
+			Key:                 "true",
+			DocCount:            2,
+			MetricsAggregations: []v7.ResponseMetricAggregation{{Name: "min_age", Value: 16.0}},
+		},
+		{
+			Key:                 "false",
+			DocCount:            1,
+			MetricsAggregations: []v7.ResponseMetricAggregation{{Name: "min_age", Value: 25.0}},
+		},
+	}, buckets["by_sick"])
+
+	months := make(map[string]int)
+	for _, bucket := range buckets["by_month"] {
+		months[bucket.Key] = bucket.DocCount
+	}
+	assert.Equal(t, map[string]int{
+		"2020-01-01T00:00:00.000Z": 2,
+		"2020-02-01T00:00:00.000Z": 1,
+		"2020-03-01T00:00:00.000Z": 1,
+	}, months)
+}
+
+func Test_FakeClient_Documents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newPeopleClient(t)
+
+	created, err := client.Index(ctx, v7.IndexConfig{
+		Index:    "people",
+		ID:       "5",
+		Document: map[string]interface{}{"Name": "George", "Age": 58},
+		OpType:   v7.OpTypeCreate,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "created", created.Result)
+
+	_, err = client.Index(ctx, v7.IndexConfig{
+		Index:    "people",
+		ID:       "5",
+		Document: map[string]interface{}{"Name": "Ringo"},
+		OpType:   v7.OpTypeCreate,
+	})
+	assert.Equal(t, v7.ErrCodeConflict, errors.GetCode(err))
+
+	updated, err := client.Update(ctx, v7.UpdateConfig{
+		Index: "people",
+		ID:    "5",
+		Doc:   map[string]interface{}{"Age": 59},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "updated", updated.Result)
+	assert.Equal(t, 2, updated.Version)
+
+	document, err := client.Get(ctx, v7.GetConfig{Index: "people", ID: "5"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"George","Age":59}`, string(document.Source))
+
+	documents, err := client.MGet(ctx, v7.MGetConfig{
+		Index:     "people",
+		Documents: []v7.MGetDocument{{ID: "1"}, {ID: "42"}},
+		Source:    v7.SourceFilter{Includes: []string{"Name"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, documents.Documents, 2)
+	assert.JSONEq(t, `{"Name":"John"}`, string(documents.Documents[0].Source))
+	assert.False(t, documents.Documents[1].Found)
+
+	_, err = client.Delete(ctx, v7.DeleteConfig{Index: "people", ID: "5"})
+	require.NoError(t, err)
+	_, err = client.Get(ctx, v7.GetConfig{Index: "people", ID: "5"})
+	assert.ErrorIs(t, err, v7.ErrDocumentNotFound)
+
+	bulk, err := client.Bulk(ctx, v7.BulkConfig{
+		Index: "people",
+		Items: []v7.BulkItem{
+			{Action: v7.BulkActionIndex, ID: "6", Document: map[string]interface{}{"Name": "Yoko"}},
+			{Action: v7.BulkActionUpdate, ID: "6", Document: map[string]interface{}{"doc": map[string]interface{}{"Age": 33}}},
+			{Action: v7.BulkActionDelete, ID: "42"},
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, bulk.Errors)
+	require.Len(t, bulk.Items, 3)
+	assert.Equal(t, 201, bulk.Items[0].Status)
+	assert.Equal(t, 200, bulk.Items[1].Status)
+	assert.Equal(t, 404, bulk.Items[2].Status)
+
+	source, found := client.Document("people", "6")
+	require.True(t, found)
+	assert.JSONEq(t, `{"Name":"Yoko","Age":33}`, string(source))
+
+	count, err := client.Count(ctx, v7.CountConfig{Indexes: []string{"people"}})
+	require.NoError(t, err)
+	assert.Equal(t, 5, count.Count)
+}
+
+func Test_FakeClient_PointInTime(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newPeopleClient(t)
+
+	id, err := client.OpenPointInTime(ctx, v7.OpenPointInTimeConfig{
+		Indexes:   []string{"people"},
+		KeepAlive: time.Minute,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, client.AddDocument("people", "5", json.RawMessage(`{"Name":"George"}`)))
+
+	response, err := client.Search(ctx, v7.SearchConfig{
+		Size:        10,
+		Sort:        v7.Sorters{Sorters: []v7.Sorter{{Field: "_shard_doc", Ascending: true}}},
+		PointInTime: &v7.PointInTime{ID: id, KeepAlive: time.Minute},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4"}, response.IDs)
+	assert.Equal(t, id, response.PointInTimeID)
+
+	require.NoError(t, client.ClosePointInTime(ctx, id))
+	_, err = client.Search(ctx, v7.SearchConfig{
+		PointInTime: &v7.PointInTime{ID: id},
+	})
+	assert.ErrorContains(t, err, "search_context_missing_exception")
+}
+
+func Test_FakeClient_Scroll(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client := newPeopleClient(t)
+
+	cursor, err := client.Scroll(ctx, v7.SearchConfig{
+		Indexes: []string{"people"},
+		Size:    3,
+	}, time.Minute)
+	require.NoError(t, err)
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for {
+		page, err := cursor.Next(ctx)
+		if errors.Is(err, v7.ErrEndOfScroll) {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, page.IDs...)
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+}
+
+func Test_FakeClient_MultiSearch(t *testing.T) {
+	t.Parallel()
+
+	type filterMust struct {
+		Names []string `es:"Name"`
+	}
+
+	client := newPeopleClient(t)
+	response, err := client.MultiSearch(context.Background(), []v7.SearchConfig{
+		{
+			Indexes: []string{"people"},
+			Size:    10,
+			Filter:  v7.Filter{Must: filterMust{Names: []string{"Mary"}}},
+		},
+		{Indexes: []string{"animals"}, Size: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Responses, 2)
+	require.Len(t, response.Errors, 2)
+	assert.Equal(t, []string{"2"}, response.Responses[0].IDs)
+	assert.NoError(t, response.Errors[0])
+	assert.ErrorContains(t, response.Errors[1], "index_not_found_exception")
+}
@@ -0,0 +1,589 @@
+package v7test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// writeRequest holds the parameters of a single document write, taken from
+// the URL of the document APIs or from an action line of a bulk.
+type writeRequest struct {
+	Index         string `json:"_index"`
+	ID            string `json:"_id"`
+	OpType        string `json:"op_type"`
+	Version       *int   `json:"version"`
+	VersionType   string `json:"version_type"`
+	IfSeqNo       *int   `json:"if_seq_no"`
+	IfPrimaryTerm *int   `json:"if_primary_term"`
+}
+
+func writeRequestFromParams(index, id string, params url.Values) writeRequest {
+	return writeRequest{
+		Index:         index,
+		ID:            id,
+		OpType:        params.Get("op_type"),
+		Version:       intParam(params, "version"),
+		VersionType:   params.Get("version_type"),
+		IfSeqNo:       intParam(params, "if_seq_no"),
+		IfPrimaryTerm: intParam(params, "if_primary_term"),
+	}
+}
+
+func intParam(params url.Values, name string) *int {
+	value, err := strconv.Atoi(params.Get(name))
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// writeResult is the result of a single document write, in the format of
+// the document APIs.
+type writeResult struct {
+	status      int
+	index       string
+	id          string
+	version     int
+	seqNo       int
+	result      string
+	primaryTerm int
+}
+
+func (r writeResult) source() map[string]interface{} {
+	return map[string]interface{}{
+		"_index":        r.index,
+		"_type":         "_doc",
+		"_id":           r.id,
+		"_version":      r.version,
+		"result":        r.result,
+		"_shards":       shardsSource(),
+		"_seq_no":       r.seqNo,
+		"_primary_term": r.primaryTerm,
+	}
+}
+
+func shardsSource() map[string]interface{} {
+	return map[string]interface{}{
+		"total":      1,
+		"successful": 1,
+		"skipped":    0,
+		"failed":     0,
+	}
+}
+
+// checkConcurrency checks the optimistic concurrency control and the
+// external versioning of @w against the @current document, which is nil if
+// it does not exist.
+func checkConcurrency(w writeRequest, current *fakeDocument) error {
+	if w.IfSeqNo != nil || w.IfPrimaryTerm != nil {
+		if current == nil {
+			return versionConflict(w.ID, "required seqNo ["+optionalInt(w.IfSeqNo)+"], but no document was found")
+		}
+		if (w.IfSeqNo != nil && *w.IfSeqNo != current.seqNo) ||
+			(w.IfPrimaryTerm != nil && *w.IfPrimaryTerm != 1) {
+			return versionConflict(w.ID, "required seqNo ["+optionalInt(w.IfSeqNo)+
+				"], primary term ["+optionalInt(w.IfPrimaryTerm)+"]. current document has seqNo ["+
+				strconv.Itoa(current.seqNo)+"] and primary term [1]")
+		}
+	}
+
+	if w.Version != nil && current != nil {
+		conflict := *w.Version <= current.version
+		if w.VersionType == "external_gte" {
+			conflict = *w.Version < current.version
+		}
+		if conflict {
+			return versionConflict(w.ID, "current version ["+strconv.Itoa(current.version)+
+				"] is higher or equal to the one provided ["+strconv.Itoa(*w.Version)+"]")
+		}
+	}
+
+	return nil
+}
+
+func optionalInt(value *int) string {
+	if value == nil {
+		return "-2"
+	}
+	return strconv.Itoa(*value)
+}
+
+// indexDocument stores the @source as the document described by @w. The
+// index is created when it does not exist.
+func (f *fakeElasticsearch) indexDocument(w writeRequest, source json.RawMessage) (writeResult, error) {
+	value, err := decode(source)
+	if _, isObject := value.(map[string]interface{}); err != nil || !isObject {
+		return writeResult{}, badRequest("mapper_parsing_exception", "failed to parse, document is empty or not an object")
+	}
+
+	index := f.getOrCreateIndex(w.Index)
+	if w.ID == "" {
+		f.lastID++
+		w.ID = "fake-" + strconv.Itoa(f.lastID)
+	}
+
+	current := index.documents[w.ID]
+	if current != nil && w.OpType == "create" {
+		return writeResult{}, versionConflict(w.ID, "document already exists (current version ["+
+			strconv.Itoa(current.version)+"])")
+	}
+	err = checkConcurrency(w, current)
+	if err != nil {
+		return writeResult{}, err
+	}
+
+	return f.store(index, w, source, value), nil
+}
+
+func (f *fakeElasticsearch) store(index *fakeIndex, w writeRequest, source json.RawMessage, value interface{}) writeResult {
+	current := index.documents[w.ID]
+
+	index.seqNo++
+	document := &fakeDocument{
+		id:      w.ID,
+		source:  compact(source),
+		value:   value,
+		version: 1,
+		seqNo:   index.seqNo - 1,
+	}
+
+	result := writeResult{status: http.StatusCreated, result: "created"}
+	if current != nil {
+		document.version = current.version + 1
+		document.position = current.position
+		result = writeResult{status: http.StatusOK, result: "updated"}
+	} else {
+		f.positions++
+		document.position = f.positions
+	}
+	if w.Version != nil {
+		document.version = *w.Version
+	}
+	index.documents[w.ID] = document
+
+	result.index = index.name
+	result.id = document.id
+	result.version = document.version
+	result.seqNo = document.seqNo
+	result.primaryTerm = 1
+	return result
+}
+
+func compact(source json.RawMessage) json.RawMessage {
+	var b bytes.Buffer
+	if json.Compact(&b, source) != nil {
+		return source
+	}
+	return b.Bytes()
+}
+
+// updateDocument applies the update @body to the document described by @w.
+// Only partial documents and upserts are supported, not scripts.
+func (f *fakeElasticsearch) updateDocument(w writeRequest, body []byte) (writeResult, error) {
+	update, err := decodeObject(body)
+	if err != nil {
+		return writeResult{}, err
+	}
+	if _, ok := update["script"]; ok {
+		return writeResult{}, notSupported("update script")
+	}
+	doc, hasDoc := update["doc"].(map[string]interface{})
+
+	index := f.getOrCreateIndex(w.Index)
+	current := index.documents[w.ID]
+	err = checkConcurrency(w, current)
+	if err != nil {
+		return writeResult{}, err
+	}
+
+	if current == nil {
+		upsert, hasUpsert := update["upsert"].(map[string]interface{})
+		if !hasUpsert && update["doc_as_upsert"] == true {
+			upsert, hasUpsert = doc, hasDoc
+		}
+		if !hasUpsert {
+			return writeResult{}, documentMissing(w.Index, w.ID)
+		}
+		return f.storeValue(index, w, upsert)
+	}
+
+	if !hasDoc {
+		return writeResult{}, badRequest("action_request_validation_exception", "Validation Failed: 1: script or doc is missing;")
+	}
+
+	merged := merge(current.value, doc)
+	if reflect.DeepEqual(merged, current.value) {
+		return writeResult{
+			status:      http.StatusOK,
+			index:       index.name,
+			id:          current.id,
+			version:     current.version,
+			seqNo:       current.seqNo,
+			result:      "noop",
+			primaryTerm: 1,
+		}, nil
+	}
+	return f.storeValue(index, w, merged)
+}
+
+func (f *fakeElasticsearch) storeValue(index *fakeIndex, w writeRequest, value interface{}) (writeResult, error) {
+	source, err := json.Marshal(value)
+	if err != nil {
+		return writeResult{}, badRequest("mapper_parsing_exception", err.Error())
+	}
+	return f.store(index, w, source, value), nil
+}
+
+// merge returns a copy of @current with the fields of the partial @doc,
+// merging objects recursively as elasticsearch does.
+func merge(current interface{}, doc map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	if object, isObject := current.(map[string]interface{}); isObject {
+		for key, value := range object {
+			merged[key] = value
+		}
+	}
+	for key, value := range doc {
+		if object, isObject := value.(map[string]interface{}); isObject {
+			if _, currentIsObject := merged[key].(map[string]interface{}); currentIsObject {
+				value = merge(merged[key], object)
+			}
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+func (f *fakeElasticsearch) deleteDocument(w writeRequest) (writeResult, error) {
+	index, ok := f.indexes[w.Index]
+	if !ok {
+		return writeResult{}, indexNotFound(w.Index)
+	}
+
+	current := index.documents[w.ID]
+	err := checkConcurrency(w, current)
+	if err != nil {
+		return writeResult{}, err
+	}
+
+	index.seqNo++
+	result := writeResult{
+		status:      http.StatusNotFound,
+		index:       index.name,
+		id:          w.ID,
+		version:     1,
+		seqNo:       index.seqNo - 1,
+		result:      "not_found",
+		primaryTerm: 1,
+	}
+	if current != nil {
+		delete(index.documents, w.ID)
+		result.status = http.StatusOK
+		result.version = current.version + 1
+		result.result = "deleted"
+	}
+	return result, nil
+}
+
+func writeResponse(result writeResult, err error) fakeResponse {
+	if err != nil {
+		return failure(err)
+	}
+	return fakeResponse{status: result.status, body: result.source()}
+}
+
+func (f *fakeElasticsearch) index(r fakeRequest, index, id string) fakeResponse {
+	return writeResponse(f.indexDocument(writeRequestFromParams(index, id, r.params), r.body))
+}
+
+func (f *fakeElasticsearch) update(r fakeRequest, index, id string) fakeResponse {
+	return writeResponse(f.updateDocument(writeRequestFromParams(index, id, r.params), r.body))
+}
+
+func (f *fakeElasticsearch) delete(r fakeRequest, index, id string) fakeResponse {
+	return writeResponse(f.deleteDocument(writeRequestFromParams(index, id, r.params)))
+}
+
+func (f *fakeElasticsearch) get(r fakeRequest, index, id string) fakeResponse {
+	filter := sourceFilterFromParams(r.params)
+
+	doc, err := f.getDocument(index, id, filter)
+	if err != nil {
+		return failure(err)
+	}
+	if doc["found"] == false {
+		return fakeResponse{status: http.StatusNotFound, body: doc}
+	}
+	return success(doc)
+}
+
+func (f *fakeElasticsearch) getDocument(index, id string, filter sourceFilter) (map[string]interface{}, error) {
+	idx, ok := f.indexes[index]
+	if !ok {
+		return nil, indexNotFound(index)
+	}
+
+	doc := map[string]interface{}{
+		"_index": index,
+		"_type":  "_doc",
+		"_id":    id,
+		"found":  false,
+	}
+	document, ok := idx.documents[id]
+	if !ok {
+		return doc, nil
+	}
+
+	doc["found"] = true
+	doc["_version"] = document.version
+	doc["_seq_no"] = document.seqNo
+	doc["_primary_term"] = 1
+	if source, ok := filter.apply(document); ok {
+		doc["_source"] = source
+	}
+	return doc, nil
+}
+
+func (f *fakeElasticsearch) mget(r fakeRequest, defaultIndex string) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+	filter := sourceFilterFromParams(r.params)
+
+	var requested []map[string]interface{}
+	for _, doc := range clauses(body["docs"]) {
+		object, _ := doc.(map[string]interface{})
+		requested = append(requested, object)
+	}
+	for _, id := range clauses(body["ids"]) {
+		requested = append(requested, map[string]interface{}{"_id": id})
+	}
+
+	docs := make([]interface{}, 0, len(requested))
+	for _, doc := range requested {
+		index, _ := doc["_index"].(string)
+		if index == "" {
+			index = defaultIndex
+		}
+		id, _ := doc["_id"].(string)
+
+		found, err := f.getDocument(index, id, filter)
+		if err != nil {
+			found = map[string]interface{}{
+				"_index": index,
+				"_type":  "_doc",
+				"_id":    id,
+				"error":  err.(*fakeError).source(),
+			}
+		}
+		docs = append(docs, found)
+	}
+
+	return success(map[string]interface{}{"docs": docs})
+}
+
+// nolint: cyclop
+func (f *fakeElasticsearch) bulk(r fakeRequest, defaultIndex string) fakeResponse {
+	lines := ndjsonLines(r.body)
+
+	var items []interface{}
+	hasErrors := false
+	for i := 0; i < len(lines); i++ {
+		var action map[string]writeRequest
+		err := json.Unmarshal(lines[i], &action)
+		if err != nil || len(action) != 1 {
+			return failure(badRequest("illegal_argument_exception", "Malformed action/metadata line ["+strconv.Itoa(i+1)+"]"))
+		}
+
+		for name, w := range action {
+			if w.Index == "" {
+				w.Index = defaultIndex
+			}
+
+			var result writeResult
+			switch name {
+			case "index", "create":
+				if i+1 >= len(lines) {
+					return failure(badRequest("illegal_argument_exception", "The bulk request must be terminated by a newline"))
+				}
+				i++
+				if name == "create" {
+					w.OpType = "create"
+				}
+				result, err = f.indexDocument(w, lines[i])
+			case "update":
+				if i+1 >= len(lines) {
+					return failure(badRequest("illegal_argument_exception", "The bulk request must be terminated by a newline"))
+				}
+				i++
+				result, err = f.updateDocument(w, lines[i])
+			case "delete":
+				result, err = f.deleteDocument(w)
+			default:
+				return failure(badRequest("illegal_argument_exception", "Unknown action ["+name+"]"))
+			}
+
+			item := map[string]interface{}{
+				"_index": w.Index,
+				"_type":  "_doc",
+				"_id":    w.ID,
+			}
+			if err != nil {
+				hasErrors = true
+				e, _ := err.(*fakeError)
+				item["status"] = e.status
+				item["error"] = map[string]interface{}{"type": e.errorType, "reason": e.reason}
+			} else {
+				item = result.source()
+				item["status"] = result.status
+			}
+			items = append(items, map[string]interface{}{name: item})
+		}
+	}
+
+	return success(map[string]interface{}{
+		"took":   0,
+		"errors": hasErrors,
+		"items":  items,
+	})
+}
+
+// sourceFilter selects the parts of the _source returned for a document.
+type sourceFilter struct {
+	disabled bool
+	includes []string
+	excludes []string
+}
+
+func sourceFilterFromParams(params url.Values) sourceFilter {
+	filter := sourceFilter{
+		includes: splitParam(params.Get("_source_includes")),
+		excludes: splitParam(params.Get("_source_excludes")),
+	}
+	switch source := params.Get("_source"); source {
+	case "", "true":
+	case "false":
+		filter.disabled = true
+	default:
+		filter.includes = append(filter.includes, splitParam(source)...)
+	}
+	return filter
+}
+
+// sourceFilterFromBody parses the "_source" of a search body, which is a
+// boolean, a field, a list of fields or an object with includes and
+// excludes.
+func sourceFilterFromBody(source interface{}) sourceFilter {
+	switch s := source.(type) {
+	case bool:
+		return sourceFilter{disabled: !s}
+	case map[string]interface{}:
+		return sourceFilter{
+			includes: stringList(s["includes"]),
+			excludes: stringList(s["excludes"]),
+		}
+	}
+	return sourceFilter{includes: stringList(source)}
+}
+
+// apply returns the filtered _source of the @document, and false when the
+// _source is disabled.
+func (s sourceFilter) apply(document *fakeDocument) (interface{}, bool) {
+	if s.disabled {
+		return nil, false
+	}
+	if len(s.includes) == 0 && len(s.excludes) == 0 {
+		return document.source, true
+	}
+	return s.filter(document.value, ""), true
+}
+
+func (s sourceFilter) filter(value interface{}, prefix string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		filtered := make(map[string]interface{})
+		for key, child := range v {
+			field := key
+			if prefix != "" {
+				field = prefix + "." + key
+			}
+			if matchAnyField(s.excludes, field) {
+				continue
+			}
+			if len(s.includes) == 0 || matchAnyField(s.includes, field) {
+				filtered[key] = sourceFilter{excludes: s.excludes}.filter(child, field)
+				continue
+			}
+			if _, isObject := child.(map[string]interface{}); isObject || isArray(child) {
+				child = s.filter(child, field)
+				if !isEmpty(child) {
+					filtered[key] = child
+				}
+			}
+		}
+		return filtered
+	case []interface{}:
+		filtered := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			filtered = append(filtered, s.filter(item, prefix))
+		}
+		return filtered
+	}
+	return value
+}
+
+// matchAnyField reports whether the @field, or one of its parents, matches
+// any of the @patterns.
+func matchAnyField(patterns []string, field string) bool {
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, field) || strings.HasPrefix(field, pattern+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func isArray(value interface{}) bool {
+	_, ok := value.([]interface{})
+	return ok
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		for _, item := range v {
+			if !isEmpty(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// stringList returns the strings of a value that is a single string or an
+// array of strings.
+func stringList(value interface{}) []string {
+	var list []string
+	for _, item := range clauses(value) {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func splitParam(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
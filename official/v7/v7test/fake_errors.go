@@ -0,0 +1,89 @@
+package v7test
+
+import (
+	"errors"
+	"net/http"
+)
+
+var errUnexpectedDocuments = errors.New("documents must be a JSON object mapping IDs to documents")
+
+// fakeError is an error replied by the fake in the format of an
+// elasticsearch error.
+type fakeError struct {
+	status    int
+	errorType string
+	reason    string
+	index     string
+}
+
+func (e *fakeError) Error() string {
+	return e.errorType + ": " + e.reason
+}
+
+func (e *fakeError) source() map[string]interface{} {
+	cause := map[string]interface{}{
+		"type":   e.errorType,
+		"reason": e.reason,
+	}
+	if e.index != "" {
+		cause["index"] = e.index
+	}
+
+	source := map[string]interface{}{
+		"root_cause": []interface{}{cause},
+	}
+	for key, value := range cause {
+		source[key] = value
+	}
+	return source
+}
+
+func badRequest(errorType, reason string) *fakeError {
+	return &fakeError{
+		status:    http.StatusBadRequest,
+		errorType: errorType,
+		reason:    reason,
+	}
+}
+
+func notSupported(feature string) *fakeError {
+	return badRequest("illegal_argument_exception", feature+" is not supported by the fake")
+}
+
+func parsingError(reason string) *fakeError {
+	return badRequest("parsing_exception", reason)
+}
+
+func indexNotFound(index string) *fakeError {
+	return &fakeError{
+		status:    http.StatusNotFound,
+		errorType: "index_not_found_exception",
+		reason:    "no such index [" + index + "]",
+		index:     index,
+	}
+}
+
+func versionConflict(id, reason string) *fakeError {
+	return &fakeError{
+		status:    http.StatusConflict,
+		errorType: "version_conflict_engine_exception",
+		reason:    "[" + id + "]: version conflict, " + reason,
+	}
+}
+
+func documentMissing(index, id string) *fakeError {
+	return &fakeError{
+		status:    http.StatusNotFound,
+		errorType: "document_missing_exception",
+		reason:    "[_doc][" + id + "]: document missing",
+		index:     index,
+	}
+}
+
+func searchContextMissing(id string) *fakeError {
+	return &fakeError{
+		status:    http.StatusNotFound,
+		errorType: "search_context_missing_exception",
+		reason:    "No search context found for id [" + id + "]",
+	}
+}
@@ -0,0 +1,493 @@
+package v7test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// scope is the part of a document a query is evaluated against. Inside a
+// nested query, the fields under the nested path are read from a single
+// nested object, so all the clauses must match the same object.
+type scope struct {
+	id     string
+	source interface{}
+	path   string
+	object interface{}
+}
+
+func documentScope(document *fakeDocument) scope {
+	return scope{id: document.id, source: document.value}
+}
+
+// values returns all the values of the @field, flattening arrays.
+func (s scope) values(field string) []interface{} {
+	if field == "_id" {
+		return []interface{}{s.id}
+	}
+	if s.path != "" {
+		if field == s.path {
+			return flatten(s.object)
+		}
+		if rest, found := strings.CutPrefix(field, s.path+"."); found {
+			return lookup(s.object, rest)
+		}
+	}
+	return lookup(s.source, field)
+}
+
+// lookup returns the values of the dotted @field in @value, flattening
+// arrays of values and of objects.
+func lookup(value interface{}, field string) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if child, ok := v[field]; ok {
+			return flatten(child)
+		}
+		name, rest, found := strings.Cut(field, ".")
+		child, ok := v[name]
+		if !ok || !found {
+			return nil
+		}
+		return lookup(child, rest)
+	case []interface{}:
+		var values []interface{}
+		for _, item := range v {
+			values = append(values, lookup(item, field)...)
+		}
+		return values
+	}
+	return nil
+}
+
+func flatten(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []interface{}
+		for _, item := range v {
+			values = append(values, flatten(item)...)
+		}
+		return values
+	}
+	return []interface{}{value}
+}
+
+// matchQuery reports whether the document in @s matches the @query, given
+// as the decoded JSON built by the querybuilders.
+// nolint: cyclop
+func matchQuery(query interface{}, s scope) (bool, error) {
+	clause, isObject := query.(map[string]interface{})
+	if !isObject || len(clause) != 1 {
+		return false, parsingError("a query must be an object with a single query type")
+	}
+
+	for kind, params := range clause {
+		switch kind {
+		case "match_all":
+			return true, nil
+		case "match_none":
+			return false, nil
+		case "term":
+			return matchTerm(params, s)
+		case "terms":
+			return matchTerms(params, s)
+		case "range":
+			return matchRange(params, s)
+		case "exists":
+			return matchExists(params, s)
+		case "bool":
+			return matchBool(params, s)
+		case "nested":
+			return matchNested(params, s)
+		case "multi_match":
+			return matchMultiMatch(params, s)
+		default:
+			return false, notSupported("query [" + kind + "]")
+		}
+	}
+	return false, nil
+}
+
+// fieldParams returns the only field of a term, terms or range query, and
+// its parameters, skipping the options that are not fields.
+func fieldParams(kind string, params interface{}) (string, interface{}, error) {
+	object, isObject := params.(map[string]interface{})
+	if !isObject {
+		return "", nil, parsingError("[" + kind + "] query malformed, no field")
+	}
+
+	for field, value := range object {
+		if field == "boost" || field == "_name" {
+			continue
+		}
+		return field, value, nil
+	}
+	return "", nil, parsingError("[" + kind + "] query malformed, no field")
+}
+
+func matchTerm(params interface{}, s scope) (bool, error) {
+	field, value, err := fieldParams("term", params)
+	if err != nil {
+		return false, err
+	}
+	if object, isObject := value.(map[string]interface{}); isObject {
+		value = object["value"]
+	}
+
+	return anyValue(s.values(field), func(v interface{}) bool {
+		return equalValues(v, value)
+	}), nil
+}
+
+func matchTerms(params interface{}, s scope) (bool, error) {
+	field, value, err := fieldParams("terms", params)
+	if err != nil {
+		return false, err
+	}
+	terms, isArray := value.([]interface{})
+	if !isArray {
+		return false, notSupported("terms lookup")
+	}
+
+	return anyValue(s.values(field), func(v interface{}) bool {
+		for _, term := range terms {
+			if equalValues(v, term) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// nolint: cyclop
+func matchRange(params interface{}, s scope) (bool, error) {
+	field, value, err := fieldParams("range", params)
+	if err != nil {
+		return false, err
+	}
+	bounds, isObject := value.(map[string]interface{})
+	if !isObject {
+		return false, parsingError("[range] query malformed, no start_object after field name")
+	}
+
+	includeLower := bounds["include_lower"] != false
+	includeUpper := bounds["include_upper"] != false
+	lower, upper := bounds["from"], bounds["to"]
+	if v, ok := bounds["gt"]; ok {
+		lower, includeLower = v, false
+	}
+	if v, ok := bounds["gte"]; ok {
+		lower, includeLower = v, true
+	}
+	if v, ok := bounds["lt"]; ok {
+		upper, includeUpper = v, false
+	}
+	if v, ok := bounds["lte"]; ok {
+		upper, includeUpper = v, true
+	}
+
+	return anyValue(s.values(field), func(v interface{}) bool {
+		if lower != nil {
+			c, comparable := compareValues(v, lower)
+			if !comparable || c < 0 || (c == 0 && !includeLower) {
+				return false
+			}
+		}
+		if upper != nil {
+			c, comparable := compareValues(v, upper)
+			if !comparable || c > 0 || (c == 0 && !includeUpper) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+func matchExists(params interface{}, s scope) (bool, error) {
+	object, isObject := params.(map[string]interface{})
+	field, isString := object["field"].(string)
+	if !isObject || !isString {
+		return false, parsingError("[exists] must be provided with a [field]")
+	}
+
+	return len(s.values(field)) > 0, nil
+}
+
+// nolint: cyclop
+func matchBool(params interface{}, s scope) (bool, error) {
+	object, isObject := params.(map[string]interface{})
+	if !isObject {
+		return false, parsingError("[bool] query malformed")
+	}
+
+	for _, occur := range []string{"must", "filter"} {
+		for _, clause := range clauses(object[occur]) {
+			matched, err := matchQuery(clause, s)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+	}
+
+	for _, clause := range clauses(object["must_not"]) {
+		matched, err := matchQuery(clause, s)
+		if err != nil || matched {
+			return false, err
+		}
+	}
+
+	should := clauses(object["should"])
+	minimumShouldMatch := 0
+	if len(should) > 0 && object["must"] == nil && object["filter"] == nil {
+		minimumShouldMatch = 1
+	}
+	if value, ok := object["minimum_should_match"]; ok {
+		n, err := strconv.Atoi(fmt.Sprint(value))
+		if err != nil {
+			return false, notSupported("minimum_should_match [" + fmt.Sprint(value) + "]")
+		}
+		minimumShouldMatch = n
+	}
+
+	matches := 0
+	for _, clause := range should {
+		matched, err := matchQuery(clause, s)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			matches++
+		}
+	}
+
+	return matches >= minimumShouldMatch, nil
+}
+
+// clauses returns the clauses of a bool occurrence, which is a single
+// query or an array of queries.
+func clauses(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	}
+	return []interface{}{value}
+}
+
+func matchNested(params interface{}, s scope) (bool, error) {
+	object, isObject := params.(map[string]interface{})
+	nestedPath, isString := object["path"].(string)
+	if !isObject || !isString {
+		return false, parsingError("[nested] requires 'path' field")
+	}
+	query, ok := object["query"]
+	if !ok {
+		return false, parsingError("[nested] requires 'query' field")
+	}
+
+	for _, nestedObject := range s.values(nestedPath) {
+		matched, err := matchQuery(query, scope{
+			id:     s.id,
+			source: s.source,
+			path:   nestedPath,
+			object: nestedObject,
+		})
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// matchMultiMatch is a simplified multi_match: the query and the values are
+// split in lowercased words. Phrase types match the words in sequence, the
+// last one as a prefix for phrase_prefix, and the other types match any of
+// the words, or all of them with the "and" operator.
+func matchMultiMatch(params interface{}, s scope) (bool, error) {
+	object, isObject := params.(map[string]interface{})
+	if !isObject {
+		return false, parsingError("[multi_match] query malformed")
+	}
+
+	words := tokenize(fmt.Sprint(object["query"]))
+	if len(words) == 0 {
+		return false, nil
+	}
+	matchType, _ := object["type"].(string)
+	operator, _ := object["operator"].(string)
+
+	for _, field := range clauses(object["fields"]) {
+		name, _, _ := strings.Cut(fmt.Sprint(field), "^")
+		for _, value := range s.values(name) {
+			if _, isObject := value.(map[string]interface{}); isObject {
+				continue
+			}
+			if matchWords(tokenize(fmt.Sprint(value)), words, matchType, operator) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func matchWords(text, words []string, matchType, operator string) bool {
+	switch matchType {
+	case "phrase", "phrase_prefix":
+		for start := 0; start+len(words) <= len(text); start++ {
+			if matchPhrase(text[start:start+len(words)], words, matchType == "phrase_prefix") {
+				return true
+			}
+		}
+		return false
+	}
+
+	matches := 0
+	for _, word := range words {
+		for _, t := range text {
+			if t == word {
+				matches++
+				break
+			}
+		}
+	}
+	if strings.EqualFold(operator, "and") {
+		return matches == len(words)
+	}
+	return matches > 0
+}
+
+func matchPhrase(text, words []string, prefix bool) bool {
+	for i, word := range words {
+		if prefix && i == len(words)-1 {
+			return strings.HasPrefix(text[i], word)
+		}
+		if text[i] != word {
+			return false
+		}
+	}
+	return true
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func anyValue(values []interface{}, f func(interface{}) bool) bool {
+	for _, value := range values {
+		if f(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares a value of a document with a value of a query.
+// Numbers are compared by value, also when one of them is a string, as
+// elasticsearch coerces them.
+func equalValues(a, b interface{}) bool {
+	if na, ok := a.(json.Number); ok {
+		if nb, ok := b.(json.Number); ok && na == nb {
+			return true
+		}
+	}
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	if _, ok := toNumber(b); ok {
+		return false
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// compareValues compares two numbers, two dates or two strings. Dates may
+// be RFC 3339 strings or epoch milliseconds.
+func compareValues(a, b interface{}) (int, bool) {
+	x, aIsNumber := toNumber(a)
+	y, bIsNumber := toNumber(b)
+	if aIsNumber && bIsNumber {
+		return compareFloats(x, y), true
+	}
+
+	ta, aIsTime := toTime(a)
+	tb, bIsTime := toTime(b)
+	if aIsTime && bIsTime {
+		return ta.Compare(tb), true
+	}
+
+	sa, aIsString := a.(string)
+	sb, bIsString := b.(string)
+	if aIsString && bIsString {
+		return strings.Compare(sa, sb), true
+	}
+	return 0, false
+}
+
+func compareFloats(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		return parseTime(v)
+	case json.Number:
+		millis, err := v.Int64()
+		return time.UnixMilli(millis).UTC(), err == nil
+	}
+	return time.Time{}, false
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// wildcardMatch reports whether the @name matches the @pattern, where "*"
+// matches any sequence of characters.
+func wildcardMatch(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
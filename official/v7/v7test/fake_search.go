@@ -0,0 +1,660 @@
+package v7test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultSearchSize = 10
+
+// searchRequest holds the parameters of a search, taken from the URL and
+// the body of a _search, or from a line pair of a _msearch.
+type searchRequest struct {
+	indexes           []string
+	ignoreUnavailable bool
+	allowNoIndices    bool
+	query             interface{}
+	aggs              map[string]interface{}
+	sort              []sorter
+	searchAfter       []interface{}
+	size              int
+	from              int
+	trackTotalHits    interface{}
+	source            sourceFilter
+	pitID             string
+}
+
+type sorter struct {
+	field     string
+	ascending bool
+}
+
+// hit is a document matched by a search, with its sort values.
+type hit struct {
+	index    string
+	document *fakeDocument
+	sort     []interface{}
+}
+
+// fakeScroll holds the hits of a scroll that were not returned yet.
+type fakeScroll struct {
+	hits    []hit
+	size    int
+	total   int
+	request searchRequest
+}
+
+func parseSearchRequest(indexes []string, params url.Values, body map[string]interface{}) (searchRequest, error) {
+	request := searchRequest{
+		indexes:           indexes,
+		ignoreUnavailable: boolParam(params, "ignore_unavailable", false),
+		allowNoIndices:    boolParam(params, "allow_no_indices", true),
+		query:             body["query"],
+		size:              defaultSearchSize,
+		trackTotalHits:    true,
+		source:            sourceFilterFromBody(body["_source"]),
+	}
+	if request.query == nil {
+		request.query = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+
+	request.aggs, _ = body["aggs"].(map[string]interface{})
+	if request.aggs == nil {
+		request.aggs, _ = body["aggregations"].(map[string]interface{})
+	}
+
+	if value, ok := numberValue(params.Get("size"), body["size"]); ok {
+		request.size = value
+	}
+	if value, ok := numberValue(params.Get("from"), body["from"]); ok {
+		request.from = value
+	}
+
+	if value := params.Get("track_total_hits"); value != "" {
+		request.trackTotalHits = value
+	} else if value, ok := body["track_total_hits"]; ok {
+		request.trackTotalHits = value
+	}
+
+	var err error
+	if value := params.Get("sort"); value != "" {
+		request.sort = parseSortParam(value)
+	} else {
+		request.sort, err = parseSortBody(body["sort"])
+		if err != nil {
+			return searchRequest{}, err
+		}
+	}
+
+	if value, ok := body["search_after"]; ok {
+		searchAfter, isArray := value.([]interface{})
+		if !isArray {
+			return searchRequest{}, parsingError("[search_after] must be an array")
+		}
+		if len(searchAfter) != len(request.sort) {
+			return searchRequest{}, badRequest("illegal_argument_exception",
+				"search_after has "+strconv.Itoa(len(searchAfter))+" value(s) but sort has "+
+					strconv.Itoa(len(request.sort))+".")
+		}
+		request.searchAfter = searchAfter
+	}
+
+	if pit, ok := body["pit"].(map[string]interface{}); ok {
+		request.pitID, _ = pit["id"].(string)
+	}
+
+	return request, nil
+}
+
+// numberValue returns the number in the URL @param, or else in the body
+// @value.
+func numberValue(param string, value interface{}) (int, bool) {
+	if param == "" && value != nil {
+		param = fmt.Sprint(value)
+	}
+	n, err := strconv.Atoi(param)
+	return n, err == nil
+}
+
+// parseSortParam parses the sort of the URL, such as "Name:asc,Age:desc".
+func parseSortParam(value string) []sorter {
+	var sorters []sorter
+	for _, item := range strings.Split(value, ",") {
+		field, order, _ := strings.Cut(item, ":")
+		sorters = append(sorters, newSorter(field, order))
+	}
+	return sorters
+}
+
+// parseSortBody parses the sort of a body, such as
+// [{"Name":{"order":"asc"}},{"Age":"desc"},"_doc"].
+func parseSortBody(value interface{}) ([]sorter, error) {
+	var sorters []sorter
+	for _, item := range clauses(value) {
+		switch s := item.(type) {
+		case string:
+			sorters = append(sorters, newSorter(s, ""))
+		case map[string]interface{}:
+			for field, options := range s {
+				order, isString := options.(string)
+				if !isString {
+					object, _ := options.(map[string]interface{})
+					order, _ = object["order"].(string)
+				}
+				sorters = append(sorters, newSorter(field, order))
+			}
+		default:
+			return nil, parsingError("malformed sort")
+		}
+	}
+	return sorters, nil
+}
+
+func newSorter(field, order string) sorter {
+	if order == "" {
+		return sorter{field: field, ascending: field != "_score"}
+	}
+	return sorter{field: field, ascending: order != "desc"}
+}
+
+// sortValue returns the value of the @document used to sort by the @s
+// sorter: the lowest value of the field for ascending sorts and the highest
+// for descending ones, or nil when the field is missing.
+func (s sorter) sortValue(index string, document *fakeDocument) interface{} {
+	switch s.field {
+	case "_id":
+		return document.id
+	case "_index":
+		return index
+	case "_doc", "_shard_doc":
+		return json.Number(strconv.Itoa(document.position))
+	case "_score":
+		return json.Number("1")
+	}
+
+	var selected interface{}
+	for _, value := range lookup(document.value, s.field) {
+		if selected == nil {
+			selected = value
+			continue
+		}
+		c, comparable := compareValues(value, selected)
+		if comparable && ((s.ascending && c < 0) || (!s.ascending && c > 0)) {
+			selected = value
+		}
+	}
+	return selected
+}
+
+// compareSortValues compares the sort values @a and @b in the order of the
+// @sorters. Missing values are sorted last.
+func compareSortValues(sorters []sorter, a, b []interface{}) int {
+	for i, s := range sorters {
+		x, y := a[i], b[i]
+		switch {
+		case x == nil && y == nil:
+			continue
+		case x == nil:
+			return 1
+		case y == nil:
+			return -1
+		}
+
+		c, comparable := compareValues(x, y)
+		if !comparable {
+			c = strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+		}
+		if !s.ascending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// indexesOf returns the indexes a search runs on: the snapshot of its
+// point in time or the current indexes.
+func (f *fakeElasticsearch) indexesOf(request searchRequest) ([]*fakeIndex, error) {
+	if request.pitID == "" {
+		return resolveIndexes(f.indexes, request.indexes, request.ignoreUnavailable, request.allowNoIndices)
+	}
+
+	snapshot, ok := f.pits[request.pitID]
+	if !ok {
+		return nil, searchContextMissing(request.pitID)
+	}
+	return resolveIndexes(snapshot, nil, true, true)
+}
+
+// matchDocuments returns all the documents that match the query of the
+// @request, sorted.
+func (f *fakeElasticsearch) matchDocuments(request searchRequest) ([]hit, error) {
+	indexes, err := f.indexesOf(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []hit
+	for _, index := range indexes {
+		for _, document := range index.sortedDocuments() {
+			matched, err := matchQuery(request.query, documentScope(document))
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+
+			h := hit{index: index.name, document: document}
+			for _, s := range request.sort {
+				h.sort = append(h.sort, s.sortValue(index.name, document))
+			}
+			hits = append(hits, h)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return compareSortValues(request.sort, hits[i].sort, hits[j].sort) < 0
+	})
+
+	return hits, nil
+}
+
+// runSearch runs the @request and returns the body of its response, the
+// matched hits after the search_after, before they are paged, and the total
+// of matched hits, which as in elasticsearch ignores the search_after.
+func (f *fakeElasticsearch) runSearch(request searchRequest) (map[string]interface{}, []hit, int, error) {
+	if len(request.searchAfter) > 0 && len(request.sort) == 0 {
+		return nil, nil, 0, badRequest("illegal_argument_exception", "Sort must contain at least one field.")
+	}
+
+	matched, err := f.matchDocuments(request)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	hits := matched
+	if len(request.searchAfter) > 0 {
+		hits = nil
+		for _, h := range matched {
+			if compareSortValues(request.sort, h.sort, request.searchAfter) > 0 {
+				hits = append(hits, h)
+			}
+		}
+	}
+
+	body := map[string]interface{}{
+		"took":      0,
+		"timed_out": false,
+		"_shards":   shardsSource(),
+	}
+	if request.pitID != "" {
+		body["pit_id"] = request.pitID
+	}
+	if len(request.aggs) > 0 {
+		documents := make([]*fakeDocument, 0, len(matched))
+		for _, h := range matched {
+			documents = append(documents, h.document)
+		}
+		body["aggregations"], err = runAggregations(request.aggs, documents)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	return body, hits, len(matched), nil
+}
+
+// hitsSource returns the "hits" of a response with the @page of hits.
+func (r searchRequest) hitsSource(total int, page []hit) map[string]interface{} {
+	sources := make([]interface{}, 0, len(page))
+	for _, h := range page {
+		source := map[string]interface{}{
+			"_index": h.index,
+			"_type":  "_doc",
+			"_id":    h.document.id,
+			"_score": 1,
+		}
+		if len(r.sort) > 0 {
+			source["_score"] = nil
+			source["sort"] = h.sort
+		}
+		if value, ok := r.source.apply(h.document); ok {
+			source["_source"] = value
+		}
+		sources = append(sources, source)
+	}
+
+	hits := map[string]interface{}{
+		"max_score": nil,
+		"hits":      sources,
+	}
+	if len(page) > 0 && len(r.sort) == 0 {
+		hits["max_score"] = 1
+	}
+
+	switch track := fmt.Sprint(r.trackTotalHits); track {
+	case "false":
+	case "true":
+		hits["total"] = map[string]interface{}{"value": total, "relation": "eq"}
+	default:
+		limit, err := strconv.Atoi(track)
+		if err == nil && total > limit {
+			hits["total"] = map[string]interface{}{"value": limit, "relation": "gte"}
+		} else {
+			hits["total"] = map[string]interface{}{"value": total, "relation": "eq"}
+		}
+	}
+
+	return hits
+}
+
+func page(hits []hit, from, size int) []hit {
+	if from >= len(hits) {
+		return nil
+	}
+	hits = hits[from:]
+	if size < len(hits) {
+		hits = hits[:size]
+	}
+	return hits
+}
+
+func (f *fakeElasticsearch) searchResponse(request searchRequest, scroll bool) (map[string]interface{}, error) {
+	body, hits, total, err := f.runSearch(request)
+	if err != nil {
+		return nil, err
+	}
+
+	first := page(hits, request.from, request.size)
+	body["hits"] = request.hitsSource(total, first)
+
+	if scroll {
+		f.lastID++
+		id := "fake-scroll-" + strconv.Itoa(f.lastID)
+		f.scrolls[id] = &fakeScroll{
+			hits:    hits[min(len(hits), request.from+len(first)):],
+			size:    request.size,
+			total:   total,
+			request: request,
+		}
+		body["_scroll_id"] = id
+	}
+
+	return body, nil
+}
+
+func (f *fakeElasticsearch) search(r fakeRequest, indexes []string) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+	request, err := parseSearchRequest(indexes, r.params, body)
+	if err != nil {
+		return failure(err)
+	}
+
+	response, err := f.searchResponse(request, r.params.Get("scroll") != "")
+	if err != nil {
+		return failure(err)
+	}
+	return success(response)
+}
+
+func (f *fakeElasticsearch) scroll(r fakeRequest) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+	id, _ := body["scroll_id"].(string)
+	if id == "" {
+		id = r.params.Get("scroll_id")
+	}
+
+	scroll, ok := f.scrolls[id]
+	if !ok {
+		return failure(searchContextMissing(id))
+	}
+
+	next := page(scroll.hits, 0, scroll.size)
+	scroll.hits = scroll.hits[len(next):]
+
+	return success(map[string]interface{}{
+		"_scroll_id": id,
+		"took":       0,
+		"timed_out":  false,
+		"_shards":    shardsSource(),
+		"hits":       scroll.request.hitsSource(scroll.total, next),
+	})
+}
+
+func (f *fakeElasticsearch) clearScroll(r fakeRequest) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+
+	freed := 0
+	for _, id := range stringList(body["scroll_id"]) {
+		if _, ok := f.scrolls[id]; ok {
+			delete(f.scrolls, id)
+			freed++
+		}
+	}
+
+	status := http.StatusOK
+	if freed == 0 {
+		status = http.StatusNotFound
+	}
+	return fakeResponse{status: status, body: map[string]interface{}{
+		"succeeded": true,
+		"num_freed": freed,
+	}}
+}
+
+func (f *fakeElasticsearch) multiSearch(r fakeRequest) fakeResponse {
+	lines := ndjsonLines(r.body)
+	if len(lines)%2 != 0 {
+		return failure(badRequest("illegal_argument_exception", "The msearch request must be terminated by a newline"))
+	}
+
+	responses := make([]interface{}, 0, len(lines)/2)
+	for i := 0; i < len(lines); i += 2 {
+		responses = append(responses, f.multiSearchItem(lines[i], lines[i+1]))
+	}
+
+	return success(map[string]interface{}{
+		"took":      0,
+		"responses": responses,
+	})
+}
+
+func (f *fakeElasticsearch) multiSearchItem(headerLine, bodyLine []byte) interface{} {
+	header, err := decodeObject(headerLine)
+	if err != nil {
+		return failure(err).body
+	}
+	body, err := decodeObject(bodyLine)
+	if err != nil {
+		return failure(err).body
+	}
+
+	params := make(url.Values)
+	for _, name := range []string{"ignore_unavailable", "allow_no_indices"} {
+		if value, ok := header[name]; ok {
+			params.Set(name, fmt.Sprint(value))
+		}
+	}
+
+	request, err := parseSearchRequest(stringList(header["index"]), params, body)
+	if err == nil {
+		var response map[string]interface{}
+		response, err = f.searchResponse(request, false)
+		if err == nil {
+			response["status"] = http.StatusOK
+			return response
+		}
+	}
+	return failure(err).body
+}
+
+func (f *fakeElasticsearch) count(r fakeRequest, indexes []string) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+	request, err := parseSearchRequest(indexes, r.params, body)
+	if err != nil {
+		return failure(err)
+	}
+
+	hits, err := f.matchDocuments(request)
+	if err != nil {
+		return failure(err)
+	}
+
+	return success(map[string]interface{}{
+		"count":   len(hits),
+		"_shards": shardsSource(),
+	})
+}
+
+func (f *fakeElasticsearch) openPointInTime(r fakeRequest, indexes []string) fakeResponse {
+	if r.params.Get("keep_alive") == "" {
+		return failure(badRequest("action_request_validation_exception", "Validation Failed: 1: [keep_alive] is not specified;"))
+	}
+
+	resolved, err := resolveIndexes(f.indexes, indexes, boolParam(r.params, "ignore_unavailable", false), true)
+	if err != nil {
+		return failure(err)
+	}
+
+	snapshot := make(map[string]*fakeIndex, len(resolved))
+	for _, index := range resolved {
+		snapshot[index.name] = index.snapshot()
+	}
+
+	f.lastID++
+	id := "fake-pit-" + strconv.Itoa(f.lastID)
+	f.pits[id] = snapshot
+
+	return success(map[string]interface{}{"id": id})
+}
+
+func (f *fakeElasticsearch) closePointInTime(r fakeRequest) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+
+	id, _ := body["id"].(string)
+	if _, ok := f.pits[id]; !ok {
+		return failure(searchContextMissing(id))
+	}
+	delete(f.pits, id)
+
+	return success(map[string]interface{}{
+		"succeeded": true,
+		"num_freed": 1,
+	})
+}
+
+// byQueryResponse runs @f on every document matched by the query of the
+// request and replies as _delete_by_query and _update_by_query do. If the
+// request is asynchronous, the operation still runs right away, and its
+// result is kept to be returned by the _tasks API.
+func (f *fakeElasticsearch) byQueryResponse(
+	r fakeRequest,
+	indexes []string,
+	counter string,
+	fn func(hit) error,
+) fakeResponse {
+	body, err := decodeObject(r.body)
+	if err != nil {
+		return failure(err)
+	}
+	if _, ok := body["script"]; ok {
+		return failure(notSupported("by query script"))
+	}
+	request, err := parseSearchRequest(indexes, r.params, body)
+	if err != nil {
+		return failure(err)
+	}
+
+	hits, err := f.matchDocuments(request)
+	if err != nil {
+		return failure(err)
+	}
+	for _, h := range hits {
+		err = fn(h)
+		if err != nil {
+			return failure(err)
+		}
+	}
+
+	response := map[string]interface{}{
+		"took":              0,
+		"timed_out":         false,
+		"total":             len(hits),
+		"updated":           0,
+		"created":           0,
+		"deleted":           0,
+		"batches":           1,
+		"version_conflicts": 0,
+		"noops":             0,
+		"failures":          []interface{}{},
+	}
+	response[counter] = len(hits)
+
+	if r.params.Get("wait_for_completion") != "false" {
+		return success(response)
+	}
+
+	f.lastID++
+	id := "fake:" + strconv.Itoa(f.lastID)
+	f.tasks[id] = map[string]interface{}{
+		"completed": true,
+		"task": map[string]interface{}{
+			"node":   "fake",
+			"id":     f.lastID,
+			"status": response,
+		},
+		"response": response,
+	}
+	return success(map[string]interface{}{"task": id})
+}
+
+func (f *fakeElasticsearch) deleteByQuery(r fakeRequest, indexes []string) fakeResponse {
+	return f.byQueryResponse(r, indexes, "deleted", func(h hit) error {
+		_, err := f.deleteDocument(writeRequest{Index: h.index, ID: h.document.id})
+		return err
+	})
+}
+
+// updateByQuery only supports updates without a script, which reindex the
+// matched documents as they are.
+func (f *fakeElasticsearch) updateByQuery(r fakeRequest, indexes []string) fakeResponse {
+	return f.byQueryResponse(r, indexes, "updated", func(h hit) error {
+		f.store(f.indexes[h.index], writeRequest{Index: h.index, ID: h.document.id}, h.document.source, h.document.value)
+		return nil
+	})
+}
+
+func (f *fakeElasticsearch) getTask(id string) fakeResponse {
+	task, ok := f.tasks[id]
+	if !ok {
+		return failure(&fakeError{
+			status:    http.StatusNotFound,
+			errorType: "resource_not_found_exception",
+			reason:    "task [" + id + "] isn't running and hasn't stored its results",
+		})
+	}
+	return success(task)
+}
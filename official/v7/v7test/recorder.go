@@ -0,0 +1,286 @@
+package v7test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/arquivei/foundationkit/errors"
+)
+
+// RecorderMode is how a Recorder handles the requests.
+type RecorderMode int
+
+const (
+	// RecorderModeReplay answers the requests with the interactions of the
+	// cassette, without reaching elasticsearch.
+	RecorderModeReplay RecorderMode = iota
+	// RecorderModeRecord sends the requests to elasticsearch and records
+	// the interactions, which are written to the cassette by Save.
+	RecorderModeRecord
+)
+
+// ErrInteractionNotFound is returned by a Recorder in replay mode when the
+// cassette has no interaction matching a request.
+var ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
+
+// Recorder is an http.RoundTripper that records the requests sent by a
+// v7.Client to elasticsearch and their responses in a cassette file, and
+// later replays them, so tests can run against real responses without a
+// live cluster:
+//
+//	recorder, err := v7test.NewRecorder("testdata/search.json", v7test.RecorderModeReplay, nil)
+//	client, err := v7.NewClientWithOptions(urls, v7.WithTransport(recorder))
+//
+// A request matches an interaction when they have the same method, path and
+// query parameters and semantically equal bodies: JSON bodies, also the
+// lines of NDJSON bodies, are compared regardless of the order of the keys
+// and of the formatting. The interactions are replayed in the order they
+// were recorded: each request is answered by the first unused interaction
+// it matches, or by the last one it matches once all of them were used.
+type Recorder struct {
+	mu           sync.Mutex
+	filename     string
+	mode         RecorderMode
+	transport    http.RoundTripper
+	interactions []*interaction
+}
+
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+	used     bool
+}
+
+type recordedRequest struct {
+	Method string     `json:"method"`
+	Path   string     `json:"path"`
+	Query  url.Values `json:"query,omitempty"`
+	Body   string     `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// NewRecorder returns a Recorder that uses the cassette in the file named
+// @filename. In replay mode the cassette is read right away. In record mode
+// the requests are sent with the @transport, or http.DefaultTransport when
+// it is nil, and Save must be called to write the cassette.
+func NewRecorder(filename string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	const op = errors.Op("v7test.NewRecorder")
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	r := &Recorder{
+		filename:  filename,
+		mode:      mode,
+		transport: transport,
+	}
+	if mode == RecorderModeRecord {
+		return r, nil
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	var c cassette
+	err = json.Unmarshal(content, &c)
+	if err != nil {
+		return nil, errors.E(op, err, errors.KV("filename", filename))
+	}
+	r.interactions = c.Interactions
+
+	return r, nil
+}
+
+// RoundTrip records or replays the request @req, depending on the mode of
+// the Recorder.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	const op = errors.Op("v7test.Recorder.RoundTrip")
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+	}
+	decoded, err := decodeBody(req.Header, body)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	request := recordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query(),
+		Body:   string(decoded),
+	}
+
+	if r.mode == RecorderModeRecord {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		response, err := r.record(req, request)
+		if err != nil {
+			return nil, errors.E(op, err)
+		}
+		return response, nil
+	}
+
+	recorded, err := r.replay(request)
+	if err != nil {
+		return nil, errors.E(op, err)
+	}
+	return recorded.httpResponse(req), nil
+}
+
+func (r *Recorder) record(req *http.Request, request recordedRequest) (*http.Response, error) {
+	response, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	recorded := recordedResponse{
+		Status: response.StatusCode,
+		Header: response.Header.Clone(),
+		Body:   string(body),
+	}
+	recorded.Header.Del("Content-Length")
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, &interaction{Request: request, Response: recorded})
+	r.mu.Unlock()
+
+	return recorded.httpResponse(req), nil
+}
+
+func (r *Recorder) replay(request recordedRequest) (recordedResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var last *interaction
+	for _, i := range r.interactions {
+		if !i.Request.matches(request) {
+			continue
+		}
+		if !i.used {
+			i.used = true
+			return i.Response, nil
+		}
+		last = i
+	}
+	if last != nil {
+		return last.Response, nil
+	}
+
+	return recordedResponse{}, errors.E(
+		ErrInteractionNotFound,
+		errors.KV("method", request.Method),
+		errors.KV("path", request.Path),
+	)
+}
+
+// Save writes the recorded interactions to the cassette file, creating its
+// directory if needed. It does nothing in replay mode.
+func (r *Recorder) Save() error {
+	const op = errors.Op("v7test.Recorder.Save")
+
+	if r.mode != RecorderModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	content, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return errors.E(op, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(r.filename), 0o755)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	err = os.WriteFile(r.filename, append(content, '\n'), 0o600)
+	if err != nil {
+		return errors.E(op, err)
+	}
+	return nil
+}
+
+func (r recordedResponse) httpResponse(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		StatusCode:    r.Status,
+		Status:        http.StatusText(r.Status),
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func (r recordedRequest) matches(other recordedRequest) bool {
+	if r.Method != other.Method || r.Path != other.Path {
+		return false
+	}
+	if len(r.Query) != 0 || len(other.Query) != 0 {
+		if !reflect.DeepEqual(r.Query, other.Query) {
+			return false
+		}
+	}
+	return equalBodies(r.Body, other.Body)
+}
+
+// equalBodies compares two request bodies. JSON bodies, and each line of
+// NDJSON bodies, are compared as decoded JSON, and other bodies as they are.
+func equalBodies(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var bodyA, bodyB interface{}
+	if json.Unmarshal([]byte(a), &bodyA) == nil && json.Unmarshal([]byte(b), &bodyB) == nil {
+		return reflect.DeepEqual(bodyA, bodyB)
+	}
+
+	linesA := ndjsonLines([]byte(a))
+	linesB := ndjsonLines([]byte(b))
+	if len(linesA) != len(linesB) || len(linesA) == 0 {
+		return false
+	}
+	for i := range linesA {
+		var valueA, valueB interface{}
+		if json.Unmarshal(linesA[i], &valueA) != nil || json.Unmarshal(linesB[i], &valueB) != nil {
+			return false
+		}
+		if !reflect.DeepEqual(valueA, valueB) {
+			return false
+		}
+	}
+	return true
+}
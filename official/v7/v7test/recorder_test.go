@@ -0,0 +1,107 @@
+package v7test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/arquivei/foundationkit/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v7 "github.com/arquivei/elasticutil/official/v7"
+)
+
+func Test_Recorder(t *testing.T) {
+	t.Parallel()
+
+	type filterMust struct {
+		Names []string `es:"Name"`
+	}
+
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "cassettes", "search.json")
+	config := v7.SearchConfig{
+		Indexes:        []string{"people"},
+		Size:           10,
+		TrackTotalHits: true,
+		Filter:         v7.Filter{Must: filterMust{Names: []string{"Mary", "Paul"}}},
+	}
+
+	recorder, err := NewRecorder(filename, RecorderModeRecord, newPeopleClient(t).es)
+	require.NoError(t, err)
+	client := v7.MustNewClientWithOptions([]string{fakeURL}, v7.WithTransport(recorder))
+	recorded, err := client.Search(ctx, config)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+
+	replayer, err := NewRecorder(filename, RecorderModeReplay, nil)
+	require.NoError(t, err)
+	client = v7.MustNewClientWithOptions([]string{fakeURL}, v7.WithTransport(replayer), v7.WithCompression())
+
+	for i := 0; i < 2; i++ {
+		replayed, err := client.Search(ctx, config)
+		require.NoError(t, err)
+		assert.Equal(t, recorded, replayed)
+		assert.Equal(t, []string{"2", "3"}, replayed.IDs)
+	}
+
+	config.Size = 20
+	_, err = client.Search(ctx, config)
+	assert.True(t, errors.Is(err, ErrInteractionNotFound))
+}
+
+func Test_equalBodies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{
+			name:     "empty",
+			expected: true,
+		},
+		{
+			name:     "key order and formatting",
+			a:        `{"query":{"term":{"Name":"John"}},"size":10}`,
+			b:        "{\"size\": 10,\n \"query\": {\"term\": {\"Name\": \"John\"}}}",
+			expected: true,
+		},
+		{
+			name:     "different values",
+			a:        `{"size":10}`,
+			b:        `{"size":20}`,
+			expected: false,
+		},
+		{
+			name:     "ndjson",
+			a:        "{\"index\":{\"_id\":\"1\"}}\n{\"Name\":\"John\",\"Age\":16}\n",
+			b:        "{\"index\": {\"_id\": \"1\"}}\n{\"Age\": 16, \"Name\": \"John\"}\n",
+			expected: true,
+		},
+		{
+			name:     "ndjson with different lines",
+			a:        "{\"index\":{\"_id\":\"1\"}}\n{\"Name\":\"John\"}\n",
+			b:        "{\"index\":{\"_id\":\"2\"}}\n{\"Name\":\"John\"}\n",
+			expected: false,
+		},
+		{
+			name:     "not json",
+			a:        "plain",
+			b:        "text",
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, equalBodies(test.a, test.b))
+		})
+	}
+}